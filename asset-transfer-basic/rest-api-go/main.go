@@ -1,26 +1,440 @@
 package main
 
 import (
+	"cmp"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/time/rate"
+	"rest-api-go/wallet"
 	"rest-api-go/web"
 )
 
 func main() {
-	//Initialize setup for Org1
-	cryptoPath := "../../test-network/organizations/peerOrganizations/org1.example.com"
-	orgConfig := web.OrgSetup{
-		OrgName:      "Org1",
-		MSPID:        "Org1MSP",
-		CertPath:     cryptoPath + "/users/User1@org1.example.com/msp/signcerts/cert.pem",
-		KeyPath:      cryptoPath + "/users/User1@org1.example.com/msp/keystore/",
-		TLSCertPath:  cryptoPath + "/peers/peer0.org1.example.com/tls/ca.crt",
-		PeerEndpoint: "dns:///localhost:7051",
-		GatewayPeer:  "peer0.org1.example.com",
+	slog.SetDefault(slog.New(web.NewTraceLogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+
+	tracerProvider, err := newTracerProvider()
+	if err != nil {
+		slog.Error("Error configuring tracing", "error", err)
+		os.Exit(1)
+	}
+	defer shutdownTracing(tracerProvider)
+
+	configs, err := loadOrgConfigs()
+	if err != nil {
+		slog.Error("Error loading organizations", "error", err)
+		os.Exit(1)
+	}
+
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		slog.Error("Error configuring authentication", "error", err)
+		os.Exit(1)
+	}
+
+	serverConfig, err := newServerConfig()
+	if err != nil {
+		slog.Error("Error configuring server", "error", err)
+		os.Exit(1)
+	}
+
+	limits, err := newLimits()
+	if err != nil {
+		slog.Error("Error configuring limits", "error", err)
+		os.Exit(1)
+	}
+	limiter := web.NewLimiter(limits)
+
+	var metricLabels []web.Rule
+	if path := os.Getenv("METRICS_LABELS_FILE"); path != "" {
+		if err := readJSON(path, &metricLabels); err != nil {
+			slog.Error("Error loading metrics labels", "error", err)
+			os.Exit(1)
+		}
+	}
+	metrics := web.NewMetrics(metricLabels...)
+
+	var setups []*web.OrgSetup
+	for _, config := range configs {
+		setup, err := newOrgSetup(config, authenticator, limiter, metrics)
+		if err != nil {
+			slog.Error("Error initializing setup", "org", config.Name, "error", err)
+			os.Exit(1)
+		}
+		setups = append(setups, setup)
+	}
+	orgs, err := web.NewOrgs(setups...)
+	if err != nil {
+		slog.Error("Error configuring organizations", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	if err := web.Serve(ctx, orgs, serverConfig); err != nil {
+		slog.Error("Error serving REST API", "error", err)
+		shutdownTracing(tracerProvider)
+		os.Exit(1)
+	}
+}
+
+// newTracerProvider sets the global tracer provider, which exports spans over OTLP/HTTP to the collector at
+// $OTEL_EXPORTER_OTLP_ENDPOINT or $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT, if set. Without a collector, spans only
+// provide the trace IDs of logs.
+func newTracerProvider() (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+		var err error
+		if exporter, err = otlptracehttp.New(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	}
+	tracerProvider, err := web.NewTracerProvider("rest-api-go", exporter)
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(tracerProvider)
+	return tracerProvider, nil
+}
+
+// shutdownTracing exports the remaining spans.
+func shutdownTracing(tracerProvider *sdktrace.TracerProvider) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		slog.Error("Error exporting spans", "error", err)
+	}
+}
+
+// orgConfig is the configuration of one organization in $ORGS_CONFIG_FILE.
+type orgConfig struct {
+	Name         string `json:"name"`
+	MSPID        string `json:"mspId"`
+	CryptoPath   string `json:"cryptoPath"`   // Organization directory created by cryptogen or the Fabric CA
+	TLSCertPath  string `json:"tlsCertPath"`  // Defaults to the TLS CA certificate of the gateway peer in cryptoPath
+	PeerEndpoint string `json:"peerEndpoint"` // For example dns:///localhost:7051
+	GatewayPeer  string `json:"gatewayPeer"`  // Host name in the gateway peer's TLS certificate
+	ChannelID    string `json:"channelId"`    // Defaults to mychannel
+	ChaincodeID  string `json:"chaincodeId"`  // Defaults to basic
+	// DefaultIdentity is the wallet label used by principals without an identity. Defaults to User1.
+	DefaultIdentity string `json:"defaultIdentity"`
+	// WalletPath is the wallet directory. Defaults to $WALLET_PATH/<name> if $WALLET_PATH is set.
+	WalletPath string `json:"walletPath"`
+	// PolicyFile is the authorization policy of the organization. Defaults to $AUTH_POLICY_FILE.
+	PolicyFile string `json:"policyFile"`
+}
+
+// loadOrgConfigs reads the organizations from the JSON array in $ORGS_CONFIG_FILE, or returns Org1 and Org2
+// of the test network if it is not set. The first organization is the default for requests that do not select one.
+func loadOrgConfigs() ([]orgConfig, error) {
+	path := os.Getenv("ORGS_CONFIG_FILE")
+	if path == "" {
+		return []orgConfig{
+			{
+				Name:         "Org1",
+				MSPID:        "Org1MSP",
+				CryptoPath:   "../../test-network/organizations/peerOrganizations/org1.example.com",
+				PeerEndpoint: "dns:///localhost:7051",
+				GatewayPeer:  "peer0.org1.example.com",
+			},
+			{
+				Name:         "Org2",
+				MSPID:        "Org2MSP",
+				CryptoPath:   "../../test-network/organizations/peerOrganizations/org2.example.com",
+				PeerEndpoint: "dns:///localhost:9051",
+				GatewayPeer:  "peer0.org2.example.com",
+			},
+		}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read organizations: %w", err)
+	}
+	var configs []orgConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse organizations: %w", err)
+	}
+	return configs, nil
+}
+
+// newOrgSetup creates the wallet and loads the authorization policy of an organization, and connects to its gateway.
+func newOrgSetup(config orgConfig, authenticator web.Authenticator, limiter *web.Limiter, metrics *web.Metrics) (*web.OrgSetup, error) {
+	walletPath := config.WalletPath
+	if path := os.Getenv("WALLET_PATH"); walletPath == "" && path != "" {
+		walletPath = filepath.Join(path, config.Name)
+	}
+	importAdmin, err := envBool("WALLET_IMPORT_ADMIN")
+	if err != nil {
+		return nil, err
+	}
+	identities, err := newWallet(walletPath, config.CryptoPath, config.MSPID, importAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wallet: %w", err)
+	}
+
+	setup := web.OrgSetup{
+		OrgName:         config.Name,
+		MSPID:           config.MSPID,
+		CryptoPath:      config.CryptoPath,
+		TLSCertPath:     cmp.Or(config.TLSCertPath, filepath.Join(config.CryptoPath, "peers", config.GatewayPeer, "tls", "ca.crt")),
+		PeerEndpoint:    config.PeerEndpoint,
+		GatewayPeer:     config.GatewayPeer,
+		ChannelID:       cmp.Or(config.ChannelID, "mychannel"),
+		ChaincodeID:     cmp.Or(config.ChaincodeID, "basic"),
+		Wallet:          identities,
+		DefaultIdentity: cmp.Or(config.DefaultIdentity, "User1"),
+		Authenticator:   authenticator,
+		Limiter:         limiter,
+		Metrics:         metrics,
 	}
+	if path := cmp.Or(config.PolicyFile, os.Getenv("AUTH_POLICY_FILE")); path != "" {
+		policy, err := web.LoadPolicy(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load authorization policy: %w", err)
+		}
+		setup.Authorizer = policy
+	} else if allowAll, err := envBool("AUTH_ALLOW_ALL"); err != nil {
+		return nil, err
+	} else if allowAll {
+		setup.Authorizer = web.AllowAll{}
+	} else {
+		return nil, fmt.Errorf("no authorization policy, set AUTH_POLICY_FILE or policyFile, or AUTH_ALLOW_ALL=true to allow every transaction")
+	}
+	return web.Initialize(setup)
+}
+
+// newServerConfig reads the listen address, TLS files and timeouts of the server from the environment.
+// Timeouts are durations such as 30s or 2m.
+func newServerConfig() (web.ServerConfig, error) {
+	config := web.ServerConfig{
+		Address:      os.Getenv("LISTEN_ADDRESS"),
+		TLSCertFile:  os.Getenv("TLS_CERT_FILE"),
+		TLSKeyFile:   os.Getenv("TLS_KEY_FILE"),
+		ClientCAFile: os.Getenv("TLS_CLIENT_CA_FILE"),
+	}
+	durations := map[string]*time.Duration{
+		"READ_TIMEOUT":     &config.ReadTimeout,
+		"WRITE_TIMEOUT":    &config.WriteTimeout,
+		"IDLE_TIMEOUT":     &config.IdleTimeout,
+		"DRAIN_DELAY":      &config.DrainDelay,
+		"SHUTDOWN_TIMEOUT": &config.ShutdownTimeout,
+	}
+	for name, duration := range durations {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		var err error
+		if *duration, err = time.ParseDuration(value); err != nil {
+			return config, fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return config, nil
+}
 
-	orgSetup, err := web.Initialize(orgConfig)
+// newWallet opens the wallet in the directory at path, or an in-memory wallet if path is empty,
+// and imports every user of the organization that the wallet does not hold yet. Admin is only imported if importAdmin
+// is set. The wallet label is the user name, for example User1 for User1@org1.example.com.
+func newWallet(path, cryptoPath, mspID string, importAdmin bool) (*wallet.Wallet, error) {
+	identities := wallet.NewInMemory()
+	if path != "" {
+		var err error
+		if identities, err = wallet.NewFileSystem(path); err != nil {
+			return nil, err
+		}
+	}
+
+	userDirs, err := filepath.Glob(filepath.Join(cryptoPath, "users", "*@*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, userDir := range userDirs {
+		label, _, _ := strings.Cut(filepath.Base(userDir), "@")
+		if label == "Admin" && !importAdmin {
+			continue
+		}
+		exists, err := identities.Exists(label)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		id, err := wallet.ImportMSP(mspID, filepath.Join(userDir, "msp"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", label, err)
+		}
+		if err := identities.Put(label, id); err != nil {
+			return nil, err
+		}
+	}
+	return identities, nil
+}
+
+// newLimits overrides web.DefaultLimits with the limits set in the environment. Zero disables a limit.
+func newLimits() (web.Limits, error) {
+	limits := web.DefaultLimits
+	numbers := map[string]*int{
+		"RATE_BURST":                  &limits.Burst,
+		"GLOBAL_RATE_BURST":           &limits.GlobalBurst,
+		"MAX_ARGS":                    &limits.MaxArgs,
+		"MAX_CONCURRENT_ENDORSEMENTS": &limits.MaxConcurrentEndorsements,
+		"MAX_BATCH_SIZE":              &limits.MaxBatchSize,
+		"MAX_BATCH_PARALLELISM":       &limits.MaxBatchParallelism,
+	}
+	for name, number := range numbers {
+		if value := os.Getenv(name); value != "" {
+			var err error
+			if *number, err = strconv.Atoi(value); err != nil {
+				return limits, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	rates := map[string]*rate.Limit{
+		"RATE_LIMIT":        &limits.Rate,
+		"GLOBAL_RATE_LIMIT": &limits.GlobalRate,
+	}
+	for name, limit := range rates {
+		if value := os.Getenv(name); value != "" {
+			perSecond, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return limits, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*limit = rate.Limit(perSecond)
+		}
+	}
+	if value := os.Getenv("MAX_BODY_BYTES"); value != "" {
+		var err error
+		if limits.MaxBodyBytes, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return limits, fmt.Errorf("invalid MAX_BODY_BYTES: %w", err)
+		}
+	}
+	durations := map[string]*time.Duration{
+		"ENDORSEMENT_WAIT": &limits.EndorsementWait,
+		"BATCH_TIMEOUT":    &limits.BatchTimeout,
+	}
+	for name, duration := range durations {
+		if value := os.Getenv(name); value != "" {
+			var err error
+			if *duration, err = time.ParseDuration(value); err != nil {
+				return limits, fmt.Errorf("invalid %s: %w", name, err)
+			}
+		}
+	}
+	return limits, nil
+}
+
+// newAuthenticator accepts API keys from $API_KEYS_FILE, JWTs verified with $JWT_HS256_SECRET or the PEM public key
+// in $JWT_RS256_PUBLIC_KEY_FILE, and TLS client certificates verified with $TLS_CLIENT_CA_FILE, whose common names
+// map to wallet labels in $TLS_CLIENT_IDENTITIES_FILE, or to the default identity if $TLS_CLIENT_ALLOW_UNMAPPED is
+// true. web.IdentityHeader, which trusts the caller, is only used
+// when $TRUST_IDENTITY_HEADER is true, and cannot be combined with the others.
+func newAuthenticator() (web.Authenticator, error) {
+	var authenticators web.Authenticators
+
+	if path := os.Getenv("API_KEYS_FILE"); path != "" {
+		keys := map[string]web.Principal{}
+		if err := readJSON(path, &keys); err != nil {
+			return nil, fmt.Errorf("failed to load API keys: %w", err)
+		}
+		authenticators = append(authenticators, web.NewAPIKeys(keys))
+	}
+
+	jwtAuthenticator := &web.JWT{
+		Issuer:         os.Getenv("JWT_ISSUER"),
+		Audience:       os.Getenv("JWT_AUDIENCE"),
+		QueryParameter: "access_token",
+	}
+	if secret := os.Getenv("JWT_HS256_SECRET"); secret != "" {
+		jwtAuthenticator.HMACSecret = []byte(secret)
+	}
+	if path := os.Getenv("JWT_RS256_PUBLIC_KEY_FILE"); path != "" {
+		publicKey, err := loadRSAPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+		jwtAuthenticator.RSAPublicKey = publicKey
+	}
+	if jwtAuthenticator.HMACSecret != nil || jwtAuthenticator.RSAPublicKey != nil {
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	if os.Getenv("TLS_CLIENT_CA_FILE") != "" {
+		clientCertificate := web.ClientCertificate{}
+		if path := os.Getenv("TLS_CLIENT_IDENTITIES_FILE"); path != "" {
+			if err := readJSON(path, &clientCertificate.Identities); err != nil {
+				return nil, fmt.Errorf("failed to load client certificate identities: %w", err)
+			}
+		}
+		allowUnmapped, err := envBool("TLS_CLIENT_ALLOW_UNMAPPED")
+		if err != nil {
+			return nil, err
+		}
+		clientCertificate.AllowUnmapped = allowUnmapped
+		authenticators = append(authenticators, clientCertificate)
+	}
+
+	trustIdentityHeader, err := envBool("TRUST_IDENTITY_HEADER")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case trustIdentityHeader && len(authenticators) > 0:
+		return nil, fmt.Errorf("TRUST_IDENTITY_HEADER cannot be combined with API keys, JWT keys or client certificates")
+	case trustIdentityHeader:
+		slog.Warn("Trusting the X-Fabric-Identity header without authenticating callers")
+		return web.IdentityHeader{}, nil
+	case len(authenticators) == 0:
+		return nil, fmt.Errorf("no authentication configured, set API_KEYS_FILE, JWT_HS256_SECRET, JWT_RS256_PUBLIC_KEY_FILE or TLS_CLIENT_CA_FILE, or TRUST_IDENTITY_HEADER=true for development")
+	}
+	return authenticators, nil
+}
+
+// readJSON parses the JSON file at path into v.
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// envBool parses the environment variable name as a boolean, false if it is not set.
+func envBool(name string) (bool, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return false, nil
+	}
+	result, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return result, nil
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+	}
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
 	if err != nil {
-		fmt.Println("Error initializing setup for Org1: ", err)
+		return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
 	}
-	web.Serve(web.OrgSetup(*orgSetup))
+	return publicKey, nil
 }
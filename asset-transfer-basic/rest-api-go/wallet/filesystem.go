@@ -0,0 +1,130 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fileExtension is the suffix of identity files, as used by the Fabric Node and Java SDK wallets.
+const fileExtension = ".id"
+
+// identityFile is the on-disk format of an identity, compatible with the Fabric Node and Java SDK wallets.
+type identityFile struct {
+	Version     int    `json:"version"`
+	Type        string `json:"type"`
+	MSPID       string `json:"mspId"`
+	Credentials struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"privateKey"`
+	} `json:"credentials"`
+}
+
+// fileSystemStore keeps each identity in <dir>/<label>.id.
+type fileSystemStore struct {
+	dir string
+}
+
+// NewFileSystemStore creates a Store in dir, creating the directory if needed.
+func NewFileSystemStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create wallet directory: %w", err)
+	}
+	return &fileSystemStore{dir: dir}, nil
+}
+
+func (s *fileSystemStore) path(label string) (string, error) {
+	if label == "" || label != filepath.Base(label) || strings.HasPrefix(label, ".") {
+		return "", fmt.Errorf("%w %q", ErrInvalidLabel, label)
+	}
+	return filepath.Join(s.dir, label+fileExtension), nil
+}
+
+func (s *fileSystemStore) Put(label string, id *Identity) error {
+	path, err := s.path(label)
+	if err != nil {
+		return err
+	}
+
+	file := identityFile{Version: 1, Type: "X.509", MSPID: id.MSPID}
+	file.Credentials.Certificate = string(id.Certificate)
+	file.Credentials.PrivateKey = string(id.PrivateKey)
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that a crash never leaves a truncated identity behind
+	tmp, err := os.CreateTemp(s.dir, "."+label+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write identity %s: %w", label, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write identity %s: %w", label, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write identity %s: %w", label, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileSystemStore) Get(label string) (*Identity, error) {
+	path, err := s.path(label)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, label)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity %s: %w", label, err)
+	}
+
+	file := identityFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse identity %s: %w", label, err)
+	}
+	if file.Type != "X.509" {
+		return nil, fmt.Errorf("identity %s has unsupported type %q", label, file.Type)
+	}
+	return &Identity{
+		MSPID:       file.MSPID,
+		Certificate: []byte(file.Credentials.Certificate),
+		PrivateKey:  []byte(file.Credentials.PrivateKey),
+	}, nil
+}
+
+func (s *fileSystemStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wallet directory: %w", err)
+	}
+	var labels []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+		labels = append(labels, strings.TrimSuffix(name, fileExtension))
+	}
+	return labels, nil
+}
+
+func (s *fileSystemStore) Remove(label string) error {
+	path, err := s.path(label)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove identity %s: %w", label, err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package wallet stores the X.509 identities that the REST server can transact as.
+package wallet
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// ErrNotFound is returned when a wallet has no identity with the requested label.
+var ErrNotFound = errors.New("identity not found in wallet")
+
+// ErrInvalidLabel is returned for labels that a wallet cannot hold, such as the empty label.
+var ErrInvalidLabel = errors.New("invalid identity label")
+
+// Identity is an enrolled X.509 identity with its PEM encoded certificate and private key.
+type Identity struct {
+	MSPID       string
+	Certificate []byte
+	PrivateKey  []byte
+}
+
+// Store persists identities by label.
+type Store interface {
+	Put(label string, id *Identity) error
+	Get(label string) (*Identity, error)
+	List() ([]string, error)
+	Remove(label string) error
+}
+
+// Wallet holds many identities in a Store.
+type Wallet struct {
+	store Store
+}
+
+// New creates a wallet backed by store.
+func New(store Store) *Wallet {
+	return &Wallet{store: store}
+}
+
+// NewInMemory creates a wallet whose identities are lost when the process exits.
+func NewInMemory() *Wallet {
+	return New(NewInMemoryStore())
+}
+
+// NewFileSystem creates a wallet that stores one file per identity in dir.
+func NewFileSystem(dir string) (*Wallet, error) {
+	store, err := NewFileSystemStore(dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(store), nil
+}
+
+// Put adds or replaces the identity with the given label, after checking that the
+// private key matches the certificate.
+func (w *Wallet) Put(label string, id *Identity) error {
+	if label == "" {
+		return fmt.Errorf("%w: label is empty", ErrInvalidLabel)
+	}
+	if id.MSPID == "" {
+		return fmt.Errorf("identity %s has no MSP ID", label)
+	}
+	if _, _, err := id.credentials(); err != nil {
+		return fmt.Errorf("identity %s: %w", label, err)
+	}
+	return w.store.Put(label, id)
+}
+
+// Get returns the identity with the given label, or an error matching ErrNotFound.
+func (w *Wallet) Get(label string) (*Identity, error) {
+	return w.store.Get(label)
+}
+
+// List returns the labels of all identities in alphabetical order.
+func (w *Wallet) List() ([]string, error) {
+	labels, err := w.store.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// Remove deletes the identity with the given label. Removing a missing identity is not an error.
+func (w *Wallet) Remove(label string) error {
+	return w.store.Remove(label)
+}
+
+// Exists reports whether the wallet holds an identity with the given label.
+func (w *Wallet) Exists(label string) (bool, error) {
+	_, err := w.store.Get(label)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Credentials returns the gateway identity and signing function for the identity with the given label.
+func (w *Wallet) Credentials(label string) (*identity.X509Identity, identity.Sign, error) {
+	id, err := w.store.Get(label)
+	if err != nil {
+		return nil, nil, err
+	}
+	x509ID, sign, err := id.credentials()
+	if err != nil {
+		return nil, nil, fmt.Errorf("identity %s: %w", label, err)
+	}
+	return x509ID, sign, nil
+}
+
+// ImportMSP reads an identity from an MSP directory as generated by cryptogen or the Fabric CA client.
+// The certificate is read from signcerts. If keystore holds several keys, the one matching the
+// certificate is used instead of whichever file happens to be listed first.
+func ImportMSP(mspID, mspDir string) (*Identity, error) {
+	certificatePEM, err := readSingleFile(filepath.Join(mspDir, "signcerts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	keyDir := filepath.Join(mspDir, "keystore")
+	files, err := os.ReadDir(keyDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		keyPEM, err := os.ReadFile(filepath.Join(keyDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file: %w", err)
+		}
+		privateKey, err := identity.PrivateKeyFromPEM(keyPEM)
+		if err != nil {
+			continue
+		}
+		if matches(certificate, privateKey) {
+			return &Identity{MSPID: mspID, Certificate: certificatePEM, PrivateKey: keyPEM}, nil
+		}
+	}
+	return nil, fmt.Errorf("no private key in %s matches certificate %s", keyDir, certificate.Subject)
+}
+
+// credentials parses the identity and checks that its private key matches its certificate.
+func (id *Identity) credentials() (*identity.X509Identity, identity.Sign, error) {
+	certificate, err := identity.CertificateFromPEM(id.Certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	privateKey, err := identity.PrivateKeyFromPEM(id.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	if !matches(certificate, privateKey) {
+		return nil, nil, errors.New("private key does not match certificate")
+	}
+
+	x509ID, err := identity.NewX509Identity(id.MSPID, certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return x509ID, sign, nil
+}
+
+func matches(certificate *x509.Certificate, privateKey crypto.PrivateKey) bool {
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return false
+	}
+	publicKey, ok := certificate.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(signer.Public())
+}
+
+func readSingleFile(dir string) ([]byte, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, file := range files {
+		if !file.IsDir() {
+			names = append(names, file.Name())
+		}
+	}
+	if len(names) != 1 {
+		return nil, fmt.Errorf("expected one file in %s, found %d", dir, len(names))
+	}
+	return os.ReadFile(filepath.Join(dir, names[0]))
+}
+
+// inMemoryStore keeps identities in a map.
+type inMemoryStore struct {
+	mu         sync.RWMutex
+	identities map[string]Identity
+}
+
+// NewInMemoryStore creates an empty in-memory Store.
+func NewInMemoryStore() Store {
+	return &inMemoryStore{identities: make(map[string]Identity)}
+}
+
+func (s *inMemoryStore) Put(label string, id *Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.identities[label] = *id
+	return nil
+}
+
+func (s *inMemoryStore) Get(label string) (*Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.identities[label]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, label)
+	}
+	return &id, nil
+}
+
+func (s *inMemoryStore) List() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	labels := make([]string, 0, len(s.identities))
+	for label := range s.identities {
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+func (s *inMemoryStore) Remove(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.identities, label)
+	return nil
+}
@@ -0,0 +1,150 @@
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newKeyPEM(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func newCertificatePEM(t *testing.T, key *ecdsa.PrivateKey, name string) []byte {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newIdentity(t *testing.T, name string) *Identity {
+	t.Helper()
+	key, keyPEM := newKeyPEM(t)
+	return &Identity{MSPID: "Org1MSP", Certificate: newCertificatePEM(t, key, name), PrivateKey: keyPEM}
+}
+
+func Test_Wallet(t *testing.T) {
+	fileSystem, err := NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, w := range map[string]*Wallet{"in-memory": NewInMemory(), "file system": fileSystem} {
+		t.Run(name, func(t *testing.T) {
+			user1, admin := newIdentity(t, "User1"), newIdentity(t, "Admin")
+			if err := w.Put("User1", user1); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if err := w.Put("Admin", admin); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			labels, err := w.List()
+			if err != nil || len(labels) != 2 || labels[0] != "Admin" || labels[1] != "User1" {
+				t.Errorf("expected [Admin User1], got %v (%v)", labels, err)
+			}
+
+			id, sign, err := w.Credentials("User1")
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if id.MspID() != "Org1MSP" {
+				t.Errorf("expected Org1MSP, got %s", id.MspID())
+			}
+			if _, err := sign(make([]byte, 32)); err != nil {
+				t.Error("unexpected sign error:", err)
+			}
+
+			if err := w.Remove("User1"); err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+			if _, err := w.Get("User1"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("expected %v after remove, got %v", ErrNotFound, err)
+			}
+			if exists, err := w.Exists("Admin"); !exists || err != nil {
+				t.Errorf("expected Admin to exist, got %v (%v)", exists, err)
+			}
+		})
+	}
+}
+
+func Test_WalletRejectsMismatchedKey(t *testing.T) {
+	id := newIdentity(t, "User1")
+	_, id.PrivateKey = newKeyPEM(t)
+	if err := NewInMemory().Put("User1", id); err == nil {
+		t.Error("expected error for private key that does not match the certificate")
+	}
+}
+
+func Test_FileSystemStoreRejectsPathLabels(t *testing.T) {
+	w, err := NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Put("../User1", newIdentity(t, "User1")); !errors.Is(err, ErrInvalidLabel) {
+		t.Errorf("expected %v for label containing a path, got %v", ErrInvalidLabel, err)
+	}
+	if _, err := w.Get("../User1"); !errors.Is(err, ErrInvalidLabel) {
+		t.Errorf("expected %v for label containing a path, got %v", ErrInvalidLabel, err)
+	}
+}
+
+func Test_ImportMSP(t *testing.T) {
+	mspDir := t.TempDir()
+	key, keyPEM := newKeyPEM(t)
+	_, otherKeyPEM := newKeyPEM(t)
+	for path, data := range map[string][]byte{
+		"signcerts/cert.pem": newCertificatePEM(t, key, "User1"),
+		"keystore/a_sk":      otherKeyPEM, // Listed first, but does not match the certificate
+		"keystore/b_sk":      keyPEM,
+	} {
+		path = filepath.Join(mspDir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	id, err := ImportMSP("Org1MSP", mspDir)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if string(id.PrivateKey) != string(keyPEM) {
+		t.Error("expected the private key matching the certificate")
+	}
+
+	if err := os.Remove(filepath.Join(mspDir, "keystore/b_sk")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ImportMSP("Org1MSP", mspDir); err == nil {
+		t.Error("expected error when no private key matches the certificate")
+	}
+}
@@ -1,10 +1,20 @@
 package web
 
 import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"time"
 
-	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+	"rest-api-go/wallet"
 )
 
 // OrgSetup contains organization's config to interact with the network.
@@ -12,20 +22,140 @@ type OrgSetup struct {
 	OrgName      string
 	MSPID        string
 	CryptoPath   string
-	CertPath     string
-	KeyPath      string
 	TLSCertPath  string
 	PeerEndpoint string
 	GatewayPeer  string
-	Gateway      client.Gateway
+
+	// ChannelID and ChaincodeID locate the asset chaincode used by the /assets endpoints.
+	ChannelID   string
+	ChaincodeID string
+
+	// Wallet holds the identities the server can transact as.
+	Wallet *wallet.Wallet
+	// DefaultIdentity is the wallet label used when the authenticator does not choose one.
+	DefaultIdentity string
+	// Authenticator maps each request to a principal and its wallet identity. Nil rejects every request.
+	Authenticator Authenticator
+	// Authorizer decides which chaincode functions each principal may call. Nil denies every call; use AllowAll
+	// to allow them all.
+	Authorizer Authorizer
+	// Limiter limits the rate, size and concurrency of transactions. Nil does not limit them.
+	Limiter *Limiter
+	// Metrics records the outcome and latency of gateway calls, and is served at /metrics. Nil records nothing.
+	Metrics *Metrics
+
+	connection   *grpc.ClientConn
+	gateways     *gatewayCache
+	transactions *transactionTracker
+	lifecycle    *lifecycle
+	boundedRules []Rule // Rules naming the label values of metrics, see metricRules
+}
+
+// ServerConfig configures the HTTP server. Zero durations select the defaults.
+type ServerConfig struct {
+	Address string // Listen address, defaults to ":3000"
+	// TLSCertFile and TLSKeyFile are the PEM server certificate and key that enable HTTPS.
+	TLSCertFile string
+	TLSKeyFile  string
+	// ClientCAFile holds the PEM CA certificates that verify client certificates, for ClientCertificate
+	// authentication. Client certificates are optional, so clients may still authenticate by other means.
+	ClientCAFile string
+
+	ReadTimeout  time.Duration // Time to read a request, defaults to 30 seconds
+	WriteTimeout time.Duration // Time to handle a request and write the response, defaults to 2 minutes, except batches
+	IdleTimeout  time.Duration // Time to keep idle connections open, defaults to 2 minutes
+	// DrainDelay is how long /readyz fails before the server stops accepting connections on shutdown,
+	// so that load balancers stop sending requests first.
+	DrainDelay time.Duration
+	// ShutdownTimeout is how long in-flight requests may take to complete on shutdown, defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+}
+
+// Serve serves the REST API of the organizations until ctx is done, then drains in-flight requests
+// and closes the gateway connections.
+func Serve(ctx context.Context, orgs *Orgs, config ServerConfig) error {
+	defer orgs.Close()
+
+	handler, err := orgs.Handler()
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return err
+	}
+	server := &http.Server{
+		Addr:              cmp.Or(config.Address, ":3000"),
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: cmp.Or(config.ReadTimeout, 30*time.Second),
+		ReadTimeout:       cmp.Or(config.ReadTimeout, 30*time.Second),
+		// Long enough for a synchronous invoke to endorse, submit and wait for the commit status
+		WriteTimeout: cmp.Or(config.WriteTimeout, 2*time.Minute),
+		IdleTimeout:  cmp.Or(config.IdleTimeout, 2*time.Minute),
+	}
+	// Shutdown does not wait for event streams, since they never become idle, nor for WebSockets
+	server.RegisterOnShutdown(orgs.lifecycle.stop)
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return err
+	}
+	served := make(chan error, 1)
+	go func() {
+		if tlsConfig != nil {
+			slog.Info("Listening", "url", fmt.Sprintf("https://%s/", listener.Addr()))
+			served <- server.ServeTLS(listener, config.TLSCertFile, config.TLSKeyFile)
+		} else {
+			slog.Info("Listening", "url", fmt.Sprintf("http://%s/", listener.Addr()))
+			served <- server.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down")
+	orgs.lifecycle.drain()
+	time.Sleep(config.DrainDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cmp.Or(config.ShutdownTimeout, 30*time.Second))
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("failed to drain requests: %w", err)
+	}
+	slog.Info("Shutdown complete")
+	return nil
 }
 
-// Serve starts http web server.
-func Serve(setups OrgSetup) {
-	http.HandleFunc("/query", setups.Query)
-	http.HandleFunc("/invoke", setups.Invoke)
-	fmt.Println("Listening (http://localhost:3000/)...")
-	if err := http.ListenAndServe(":3000", nil); err != nil {
-		fmt.Println(err)
+// tlsConfig returns the server TLS configuration, or nil to serve plain HTTP.
+func (config ServerConfig) tlsConfig() (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		if config.ClientCAFile != "" {
+			return nil, errors.New("client certificates require a TLS server certificate and key")
+		}
+		return nil, nil
+	}
+	if config.TLSCertFile == "" || config.TLSKeyFile == "" {
+		return nil, errors.New("TLS requires both a server certificate and key")
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA certificates: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in %s", config.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
 	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,41 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ReadAsset evaluates ReadAsset on the asset chaincode.
+func (setup *OrgSetup) ReadAsset(ctx context.Context, request ReadAssetRequestObject) (ReadAssetResponseObject, error) {
+	result, err := setup.evaluate(ctx, setup.ChannelID, setup.ChaincodeID, "ReadAsset", []string{request.Id})
+	if err != nil {
+		return nil, err
+	}
+	asset := ReadAsset200JSONResponse{}
+	if err := json.Unmarshal(result, &asset); err != nil {
+		return nil, fmt.Errorf("failed to parse asset %s: %w", request.Id, err)
+	}
+	return asset, nil
+}
+
+// CreateAsset submits CreateAsset on the asset chaincode.
+func (setup *OrgSetup) CreateAsset(ctx context.Context, request CreateAssetRequestObject) (CreateAssetResponseObject, error) {
+	asset := request.Body
+	response, err := setup.submit(ctx, setup.ChannelID, setup.ChaincodeID, "CreateAsset",
+		[]string{asset.ID, asset.Color, strconv.Itoa(asset.Size), asset.Owner, strconv.Itoa(asset.AppraisedValue)})
+	if err != nil {
+		return nil, err
+	}
+	return CreateAsset201JSONResponse{TransactionSuccessJSONResponse(*response)}, nil
+}
+
+// TransferAsset submits TransferAsset on the asset chaincode. The result is the previous owner.
+func (setup *OrgSetup) TransferAsset(ctx context.Context, request TransferAssetRequestObject) (TransferAssetResponseObject, error) {
+	response, err := setup.submit(ctx, setup.ChannelID, setup.ChaincodeID, "TransferAsset", []string{request.Id, request.Body.Owner})
+	if err != nil {
+		return nil, err
+	}
+	return TransferAsset200JSONResponse{TransactionSuccessJSONResponse(*response)}, nil
+}
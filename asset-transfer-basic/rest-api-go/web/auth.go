@@ -0,0 +1,164 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// DefaultIdentityHeader is the request header that selects the signing identity by wallet label.
+const DefaultIdentityHeader = "X-Fabric-Identity"
+
+// DefaultAPIKeyHeader is the request header that carries an API key.
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// ErrNoCredentials is returned by an Authenticator when the request does not carry its kind of credentials,
+// so that Authenticators can try the next one.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrNoIdentity is returned by an Authenticator when it authenticates the caller but maps it to no wallet identity.
+// Such requests are rejected with 403 Forbidden.
+var ErrNoIdentity = errors.New("no wallet identity")
+
+// errNoAuthenticator rejects the requests of an OrgSetup without an Authenticator.
+var errNoAuthenticator = &requestError{status: http.StatusUnauthorized, code: "UNAUTHENTICATED", err: errors.New("no authentication configured")}
+
+// Principal is the authenticated caller of a request and the wallet identity it transacts as.
+type Principal struct {
+	Name     string `json:"name"`
+	Identity string `json:"identity,omitempty"` // Wallet label; empty selects OrgSetup.DefaultIdentity
+}
+
+// Authenticator establishes the principal of an HTTP request.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Authenticators tries each authenticator in turn, skipping those that find no credentials in the request.
+// The first authenticator that finds credentials decides the outcome.
+type Authenticators []Authenticator
+
+// Authenticate implements Authenticator.
+func (a Authenticators) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range a {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// IdentityHeader selects the wallet identity named in a request header without verifying the caller.
+// It suits development, or deployments behind a proxy that authenticates users and sets the header.
+type IdentityHeader struct {
+	Header string // Defaults to DefaultIdentityHeader
+}
+
+// Authenticate implements Authenticator.
+func (a IdentityHeader) Authenticate(r *http.Request) (*Principal, error) {
+	header := a.Header
+	if header == "" {
+		header = DefaultIdentityHeader
+	}
+	label := r.Header.Get(header)
+	return &Principal{Name: label, Identity: label}, nil
+}
+
+// APIKeys authenticates requests by a static API key sent in a request header.
+type APIKeys struct {
+	Header string // Defaults to DefaultAPIKeyHeader
+	keys   map[[sha256.Size]byte]Principal
+}
+
+// NewAPIKeys creates an APIKeys authenticator for the principals keyed by their API key.
+func NewAPIKeys(keys map[string]Principal) *APIKeys {
+	a := &APIKeys{keys: make(map[[sha256.Size]byte]Principal, len(keys))}
+	for key, principal := range keys {
+		// Look keys up by digest so that lookup time does not depend on how much of a guessed key is correct
+		a.keys[sha256.Sum256([]byte(key))] = principal
+	}
+	return a
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	header := a.Header
+	if header == "" {
+		header = DefaultAPIKeyHeader
+	}
+	key := r.Header.Get(header)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	principal, ok := a.keys[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+	return &principal, nil
+}
+
+// ClientCertificate authenticates requests by the TLS client certificate verified by the server,
+// so it only applies when the server requests client certificates.
+// The principal name is the certificate's subject common name.
+type ClientCertificate struct {
+	// Identities maps principal names to wallet labels.
+	Identities map[string]string
+	// AllowUnmapped lets principals that are not listed in Identities transact as OrgSetup.DefaultIdentity.
+	// Otherwise they fail with ErrNoIdentity.
+	AllowUnmapped bool
+}
+
+// Authenticate implements Authenticator.
+func (a ClientCertificate) Authenticate(r *http.Request) (*Principal, error) {
+	// VerifiedChains is only set when the certificate chains to a CA trusted by the server
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return nil, ErrNoCredentials
+	}
+	name := r.TLS.VerifiedChains[0][0].Subject.CommonName
+	if name == "" {
+		return nil, errors.New("client certificate has no common name")
+	}
+	identity, ok := a.Identities[name]
+	if !ok && !a.AllowUnmapped {
+		return nil, fmt.Errorf("%w for client certificate %s", ErrNoIdentity, name)
+	}
+	return &Principal{Name: name, Identity: identity}, nil
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal stored by the authentication middleware.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// authenticate runs the configured Authenticator and stores the principal in the request context.
+// Without an Authenticator every request is rejected.
+func (setup *OrgSetup) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if setup.Authenticator == nil {
+			writeError(w, r, errNoAuthenticator)
+			return
+		}
+		principal, err := setup.Authenticator.Authenticate(r)
+		if err != nil {
+			slog.WarnContext(r.Context(), "Authentication failed", "error", err)
+			authErr := &requestError{status: http.StatusUnauthorized, code: "UNAUTHENTICATED", err: err}
+			if errors.Is(err, ErrNoIdentity) {
+				authErr.status, authErr.code = http.StatusForbidden, "PERMISSION_DENIED"
+			}
+			writeError(w, r, authErr)
+			return
+		}
+		if principal.Identity == "" {
+			principal.Identity = setup.DefaultIdentity
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
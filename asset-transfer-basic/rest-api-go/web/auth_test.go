@@ -0,0 +1,247 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"rest-api-go/wallet"
+)
+
+func TestAPIKeys(t *testing.T) {
+	authenticator := NewAPIKeys(map[string]Principal{"secret": {Name: "alice", Identity: "User1"}})
+
+	r := httptest.NewRequest(http.MethodGet, "/query", nil)
+	if _, err := authenticator.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials without a key, got %v", err)
+	}
+
+	r.Header.Set(DefaultAPIKeyHeader, "secret")
+	principal, err := authenticator.Authenticate(r)
+	if err != nil || principal.Name != "alice" || principal.Identity != "User1" {
+		t.Errorf("expected alice as User1, got %+v (%v)", principal, err)
+	}
+
+	r.Header.Set(DefaultAPIKeyHeader, "guess")
+	if _, err := authenticator.Authenticate(r); err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected invalid key error, got %v", err)
+	}
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func bearerRequest(token string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/query", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticator := &JWT{HMACSecret: secret, RSAPublicKey: &rsaKey.PublicKey, Audience: "asset-api"}
+	expiry := time.Now().Add(time.Hour).Unix()
+
+	for name, testCase := range map[string]struct {
+		token string
+		valid bool
+	}{
+		"HS256":          {signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "aud": "asset-api", "exp": expiry, DefaultIdentityClaim: "Admin"}), true},
+		"RS256":          {signToken(t, jwt.SigningMethodRS256, rsaKey, jwt.MapClaims{"sub": "alice", "aud": "asset-api", "exp": expiry, DefaultIdentityClaim: "Admin"}), true},
+		"wrong RSA key":  {signToken(t, jwt.SigningMethodRS256, otherKey, jwt.MapClaims{"sub": "alice", "aud": "asset-api", "exp": expiry}), false},
+		"expired":        {signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "aud": "asset-api", "exp": time.Now().Add(-time.Hour).Unix()}), false},
+		"no expiry":      {signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "aud": "asset-api"}), false},
+		"wrong audience": {signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "alice", "aud": "other", "exp": expiry}), false},
+		"unsigned":       {signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"sub": "alice", "aud": "asset-api", "exp": expiry}), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(bearerRequest(testCase.token))
+			if !testCase.valid {
+				if err == nil || errors.Is(err, ErrNoCredentials) {
+					t.Errorf("expected invalid token error, got %+v (%v)", principal, err)
+				}
+				return
+			}
+			if err != nil || principal.Name != "alice" || principal.Identity != "Admin" {
+				t.Errorf("expected alice as Admin, got %+v (%v)", principal, err)
+			}
+		})
+	}
+
+	t.Run("HS256 without secret", func(t *testing.T) {
+		authenticator := &JWT{RSAPublicKey: &rsaKey.PublicKey}
+		// An HS256 token signed with the RSA public key must not verify against it
+		publicKey, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		token := signToken(t, jwt.SigningMethodHS256, publicKey, jwt.MapClaims{"sub": "alice", "exp": expiry})
+		if _, err := authenticator.Authenticate(bearerRequest(token)); err == nil {
+			t.Error("expected HS256 token to be rejected")
+		}
+	})
+}
+
+func TestClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: newSerial(), Subject: pkix.Name{CommonName: "alice"}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := ClientCertificate{Identities: map[string]string{"alice": "Admin"}}
+	r := httptest.NewRequest(http.MethodGet, "/query", nil)
+	if _, err := authenticator.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials without TLS, got %v", err)
+	}
+
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+	principal, err := authenticator.Authenticate(r)
+	if err != nil || principal.Name != "alice" || principal.Identity != "Admin" {
+		t.Errorf("expected alice as Admin, got %+v (%v)", principal, err)
+	}
+
+	unmapped := ClientCertificate{Identities: map[string]string{"bob": "User1"}}
+	if _, err := unmapped.Authenticate(r); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("expected ErrNoIdentity for unmapped alice, got %v", err)
+	}
+	unmapped.AllowUnmapped = true
+	principal, err = unmapped.Authenticate(r)
+	if err != nil || principal.Name != "alice" || principal.Identity != "" {
+		t.Errorf("expected alice with the default identity, got %+v (%v)", principal, err)
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	authenticator := Authenticators{NewAPIKeys(map[string]Principal{"secret": {Name: "alice"}}), &JWT{HMACSecret: []byte("secret")}}
+
+	r := httptest.NewRequest(http.MethodGet, "/query", nil)
+	if _, err := authenticator.Authenticate(r); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected ErrNoCredentials, got %v", err)
+	}
+
+	r = bearerRequest("not-a-token")
+	if _, err := authenticator.Authenticate(r); err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Errorf("expected invalid token error, got %v", err)
+	}
+
+	r.Header.Set(DefaultAPIKeyHeader, "secret")
+	if principal, err := authenticator.Authenticate(r); err != nil || principal.Name != "alice" {
+		t.Errorf("expected API key to authenticate alice, got %+v (%v)", principal, err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := Policy{
+		"alice":      {{Channel: "mychannel", Chaincode: "basic"}},
+		AnyPrincipal: {{Channel: "mychannel", Chaincode: "basic", Function: "ReadAsset"}},
+	}
+	alice, bob := &Principal{Name: "alice"}, &Principal{Name: "bob"}
+
+	for _, testCase := range []struct {
+		principal *Principal
+		channel   string
+		function  string
+		allowed   bool
+	}{
+		{alice, "mychannel", "CreateAsset", true},
+		{alice, "otherchannel", "ReadAsset", false},
+		{bob, "mychannel", "ReadAsset", true},
+		{bob, "mychannel", "CreateAsset", false},
+	} {
+		err := policy.Authorize(testCase.principal, testCase.channel, "basic", testCase.function)
+		if (err == nil) != testCase.allowed {
+			t.Errorf("%s calling %s on %s: expected allowed=%t, got %v", testCase.principal.Name, testCase.function, testCase.channel, testCase.allowed, err)
+		}
+	}
+}
+
+func TestHandlerRejectsUnauthorizedRequests(t *testing.T) {
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Authorizer:    Policy{"bob": {{Function: "ReadAsset"}}},
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/query?channelid=mychannel&chaincodeid=basic&function=GetAllAssets", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without an API key, got %d: %s", w.Code, w.Body.String())
+	}
+
+	r.Header.Set(DefaultAPIKeyHeader, "secret")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a function outside the policy, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlerFailsClosed(t *testing.T) {
+	query := "/query?channelid=mychannel&chaincodeid=basic&function=GetAllAssets"
+	for name, testCase := range map[string]struct {
+		setup  *OrgSetup
+		status int
+	}{
+		"without authenticator": {&OrgSetup{DefaultIdentity: "User1", Authorizer: AllowAll{}}, http.StatusUnauthorized},
+		"without authorizer":    {&OrgSetup{DefaultIdentity: "User1", Authenticator: IdentityHeader{}}, http.StatusForbidden},
+		"unmapped certificate":  {&OrgSetup{DefaultIdentity: "User1", Authenticator: ClientCertificate{}, Authorizer: AllowAll{}}, http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			handler, err := testCase.setup.Handler()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest(http.MethodGet, query, nil)
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "alice"}}}}}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != testCase.status {
+				t.Errorf("expected %d, got %d: %s", testCase.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if _, err := Initialize(OrgSetup{Wallet: wallet.NewInMemory(), Authenticator: IdentityHeader{}}); err == nil {
+		t.Error("expected Initialize to fail without an authorizer")
+	}
+}
+
+func newSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	return serial
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InvokeBatch submits the transactions in the request body concurrently, with bounded parallelism, and responds
+// with the outcome of each once all of them complete. Each transaction is authorized and limited like an Invoke,
+// except that it waits for its turn under the rate limits. Transactions that have not started within the BatchTimeout
+// of the Limiter are skipped.
+func (setup *OrgSetup) InvokeBatch(ctx context.Context, request InvokeBatchRequestObject) (InvokeBatchResponseObject, error) {
+	if request.Body == nil {
+		return nil, badRequest("request body is required")
+	}
+	transactions := request.Body.Transactions
+	if err := setup.Limiter.allowBatch(len(transactions)); err != nil {
+		return nil, err
+	}
+	parallelism := setup.Limiter.batchParallelism(request.Body.Parallelism)
+	slog.InfoContext(ctx, "Received batch Invoke request", "transactions", len(transactions), "parallelism", parallelism)
+
+	ctx = context.WithValue(ctx, waitForRateKey{}, true)
+	startCtx := ctx
+	if timeout := setup.Limiter.batchTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		startCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	results := make([]BatchResult, len(transactions))
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	running := make(chan struct{}, parallelism)
+	for i, transaction := range transactions {
+		select {
+		case running <- struct{}{}:
+		case <-startCtx.Done():
+		}
+		if startCtx.Err() != nil || (request.Body.StopOnFailure && failed.Load()) {
+			for j := i; j < len(transactions); j++ {
+				results[j] = BatchResult{Status: BatchSkipped}
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-running }()
+			results[i] = setup.submitBatchTransaction(ctx, i, transaction)
+			if results[i].Status != BatchCommitted {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+	return InvokeBatch200JSONResponse{Results: results}, nil
+}
+
+// extendBatchWriteDeadline replaces the server's WriteTimeout for batch invokes, which may take longer, with the
+// time a batch may take to start its transactions and for the last of them to commit.
+func (setup *OrgSetup) extendBatchWriteDeadline(f StrictHandlerFunc, operationID string) StrictHandlerFunc {
+	if operationID != "InvokeBatch" {
+		return f
+	}
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		var deadline time.Time
+		if timeout := setup.Limiter.batchTimeout(); timeout > 0 {
+			deadline = time.Now().Add(timeout + batchCompletionTime)
+		}
+		if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.WarnContext(ctx, "Failed to extend the write deadline of batch", "error", err)
+		}
+		return f(ctx, w, r, request)
+	}
+}
+
+// submitBatchTransaction submits the transaction at index i of a batch in its own span, and waits for it to commit.
+func (setup *OrgSetup) submitBatchTransaction(ctx context.Context, i int, transaction TransactionRequest) BatchResult {
+	ctx, span := tracer.Start(ctx, "batch transaction", trace.WithAttributes(attribute.Int("batch.index", i)))
+	defer span.End()
+
+	response, err := setup.submit(ctx, transaction.ChannelId, transaction.ChaincodeId, transaction.Function, transaction.Args, proposalOptions(transaction)...)
+	if err == nil {
+		return BatchResult{
+			TxId:           response.TxId,
+			Status:         BatchCommitted,
+			Result:         response.Result,
+			BlockNumber:    response.BlockNumber,
+			ValidationCode: response.ValidationCode,
+		}
+	}
+
+	body := newErrorBody(err)
+	slog.WarnContext(ctx, "Batch transaction failed", "index", i, "status", body.Status, "code", body.Code, "error", body.Message)
+	result := BatchResult{TxId: body.TxId, Status: BatchError, ValidationCode: body.ValidationCode, Error: &body}
+	var commitErr *commitError
+	if errors.As(err, &commitErr) {
+		result.Status = BatchFailed
+		result.BlockNumber = commitErr.status.BlockNumber
+	}
+	return result
+}
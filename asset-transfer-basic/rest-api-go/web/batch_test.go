@@ -0,0 +1,160 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func invokeBatch(t *testing.T, handler http.Handler, body string) (int, BatchResponse) {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/invoke/batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(DefaultAPIKeyHeader, "secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	var response BatchResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w.Code, response
+}
+
+func TestInvokeBatch(t *testing.T) {
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Authorizer:    Policy{"bob": {{Function: "CreateAsset"}}},
+		Limiter:       NewLimiter(Limits{MaxBatchSize: 3}),
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied := `{"channelId":"mychannel","chaincodeId":"basic","function":"DeleteAsset","args":["asset1"]}`
+	// bob has no identity, so allowed transactions fail once they reach the gateway
+	allowed := `{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset","args":["asset1"]}`
+
+	for _, test := range []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "every transaction",
+			body:     `{"transactions":[` + denied + `,` + allowed + `]}`,
+			expected: []string{"PERMISSION_DENIED", "UNAUTHENTICATED"},
+		},
+		{
+			name:     "stop on failure",
+			body:     `{"transactions":[` + denied + `,` + allowed + `,` + allowed + `],"parallelism":1,"stopOnFailure":true}`,
+			expected: []string{"PERMISSION_DENIED", "", ""},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			code, response := invokeBatch(t, handler, test.body)
+			if code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", code)
+			}
+			if len(response.Results) != len(test.expected) {
+				t.Fatalf("expected %d results, got %v", len(test.expected), response.Results)
+			}
+			for i, expected := range test.expected {
+				result := response.Results[i]
+				switch {
+				case expected == "" && result.Status != BatchSkipped:
+					t.Errorf("expected transaction %d to be skipped, got %+v", i, result)
+				case expected != "" && (result.Status != BatchError || result.Error == nil || result.Error.Code != expected):
+					t.Errorf("expected transaction %d to fail with %s, got %+v", i, expected, result)
+				}
+			}
+		})
+	}
+
+	if code, _ := invokeBatch(t, handler, `{"transactions":[`+allowed+`,`+allowed+`,`+allowed+`,`+allowed+`]}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a batch over the limit, got %d", code)
+	}
+	if code, _ := invokeBatch(t, handler, `{"transactions":[]}`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an empty batch, got %d", code)
+	}
+}
+
+// slowAuthorizer denies every transaction after a delay, so that batches take time without a gateway.
+type slowAuthorizer time.Duration
+
+func (a slowAuthorizer) Authorize(*Principal, string, string, string) error {
+	time.Sleep(time.Duration(a))
+	return errors.New("denied")
+}
+
+const slowBatch = `{"transactions":[
+	{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset"},
+	{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset"},
+	{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset"}
+],"parallelism":1}`
+
+func TestInvokeBatchOutlastsWriteTimeout(t *testing.T) {
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Authorizer:    slowAuthorizer(100 * time.Millisecond),
+		Limiter:       NewLimiter(Limits{BatchTimeout: time.Minute}),
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	request, err := http.NewRequest(http.MethodPost, server.URL+"/invoke/batch", strings.NewReader(slowBatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(DefaultAPIKeyHeader, "secret")
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal("expected a response after the write timeout:", err)
+	}
+	defer response.Body.Close()
+	var body BatchResponse
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatal("expected a complete response after the write timeout:", err)
+	}
+	if len(body.Results) != 3 {
+		t.Fatalf("expected 3 results, got %v", body.Results)
+	}
+}
+
+func TestInvokeBatchSkipsTransactionsAfterTimeout(t *testing.T) {
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Authorizer:    slowAuthorizer(200 * time.Millisecond),
+		Limiter:       NewLimiter(Limits{BatchTimeout: 300 * time.Millisecond}),
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, response := invokeBatch(t, handler, slowBatch)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	var statuses []BatchResultStatus
+	for _, result := range response.Results {
+		statuses = append(statuses, result.Status)
+	}
+	// The second transaction starts within the timeout, the third does not
+	if expected := []BatchResultStatus{BatchError, BatchError, BatchSkipped}; !slices.Equal(statuses, expected) {
+		t.Errorf("expected %v, got %v", expected, statuses)
+	}
+}
@@ -0,0 +1,354 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// keepAliveInterval is how often an idle event stream sends a keep-alive, so that proxies do not close it.
+const keepAliveInterval = 15 * time.Second
+
+// errEventStreamClosed reports that the gateway ended an event stream, usually because the connection failed.
+var errEventStreamClosed = &requestError{status: http.StatusServiceUnavailable, code: "UNAVAILABLE", err: errors.New("event stream closed by the gateway")}
+
+// upgrader only accepts WebSockets opened by pages of the same origin, since browsers send client certificates
+// with WebSocket requests from any page.
+var upgrader = websocket.Upgrader{}
+
+// streamEvent is one event of a stream, with the id that resumes the stream after it.
+type streamEvent struct {
+	ID   string
+	Data any
+}
+
+// eventCheckpoint is the position to resume an event stream from, implementing client.Checkpoint.
+type eventCheckpoint struct {
+	blockNumber   uint64
+	transactionID string
+}
+
+func (c eventCheckpoint) BlockNumber() uint64 {
+	return c.blockNumber
+}
+
+func (c eventCheckpoint) TransactionID() string {
+	return c.transactionID
+}
+
+// lastEventID returns the id of the last event a client received, from the Last-Event-ID header sent by
+// reconnecting EventSources or the lastEventId parameter.
+func lastEventID(r *http.Request) string {
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("lastEventId")
+}
+
+// eventOptions returns the start position of an event stream: after the last event received if the client
+// is resuming, otherwise the startBlock parameter, otherwise the next block.
+func eventOptions(r *http.Request, checkpoint func(id string) (eventCheckpoint, error)) ([]client.ChaincodeEventsOption, error) {
+	if id := lastEventID(r); id != "" {
+		position, err := checkpoint(id)
+		if err != nil {
+			return nil, badRequest("invalid last event id %q", id)
+		}
+		return []client.ChaincodeEventsOption{client.WithCheckpoint(position)}, nil
+	}
+	if startBlock := r.URL.Query().Get("startBlock"); startBlock != "" {
+		blockNumber, err := strconv.ParseUint(startBlock, 10, 64)
+		if err != nil {
+			return nil, badRequest("invalid startBlock %q", startBlock)
+		}
+		return []client.ChaincodeEventsOption{client.WithStartBlock(blockNumber)}, nil
+	}
+	return nil, nil
+}
+
+// chaincodeEventCheckpoint resumes after the chaincode event with id <blockNumber>:<txId>.
+func chaincodeEventCheckpoint(id string) (eventCheckpoint, error) {
+	block, txID, found := strings.Cut(id, ":")
+	blockNumber, err := strconv.ParseUint(block, 10, 64)
+	if !found || err != nil || txID == "" {
+		return eventCheckpoint{}, fmt.Errorf("invalid chaincode event id %q", id)
+	}
+	return eventCheckpoint{blockNumber: blockNumber, transactionID: txID}, nil
+}
+
+// blockEventCheckpoint resumes at the block after the block event with the given id.
+func blockEventCheckpoint(id string) (eventCheckpoint, error) {
+	blockNumber, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return eventCheckpoint{}, fmt.Errorf("invalid block event id %q", id)
+	}
+	return eventCheckpoint{blockNumber: blockNumber + 1}, nil
+}
+
+// ChaincodeEvents streams the events of a chaincode to the client.
+func (setup *OrgSetup) ChaincodeEvents(w http.ResponseWriter, r *http.Request) {
+	channelID := queryOrDefault(r, "channel", setup.ChannelID)
+	chaincodeID := queryOrDefault(r, "chaincode", setup.ChaincodeID)
+	options, err := eventOptions(r, chaincodeEventCheckpoint)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// Events are not a function call, so they are allowed by rules for any function of the chaincode
+	if err := setup.authorize(r.Context(), channelID, chaincodeID, ""); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	gateway, err := setup.requestGateway(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	events, err := gateway.GetNetwork(channelID).ChaincodeEvents(ctx, chaincodeID, options...)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	streamEvents(w, r, setup.lifecycle.stopped, cancel, events, func(event *client.ChaincodeEvent) streamEvent {
+		return streamEvent{
+			ID: fmt.Sprintf("%d:%s", event.BlockNumber, event.TransactionID),
+			Data: ChaincodeEvent{
+				BlockNumber:   event.BlockNumber,
+				TxId:          event.TransactionID,
+				ChaincodeName: event.ChaincodeName,
+				EventName:     event.EventName,
+				Payload:       chaincodeResult(event.Payload),
+			},
+		}
+	})
+}
+
+// BlockEvents streams a summary of each block committed to a channel to the client.
+func (setup *OrgSetup) BlockEvents(w http.ResponseWriter, r *http.Request) {
+	channelID := queryOrDefault(r, "channel", setup.ChannelID)
+	options, err := eventOptions(r, blockEventCheckpoint)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := setup.authorize(r.Context(), channelID, "", ""); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	gateway, err := setup.requestGateway(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	blockOptions := make([]client.BlockEventsOption, len(options))
+	for i, option := range options {
+		blockOptions[i] = client.BlockEventsOption(option)
+	}
+	blocks, err := gateway.GetNetwork(channelID).BlockEvents(ctx, blockOptions...)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	streamEvents(w, r, setup.lifecycle.stopped, cancel, blocks, func(block *common.Block) streamEvent {
+		event := newBlockEvent(block)
+		return streamEvent{ID: strconv.FormatUint(event.BlockNumber, 10), Data: event}
+	})
+}
+
+func queryOrDefault(r *http.Request, name string, defaultValue string) string {
+	if value := r.URL.Query().Get(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// newBlockEvent summarizes the transactions in a block.
+func newBlockEvent(block *common.Block) BlockEvent {
+	event := BlockEvent{BlockNumber: block.GetHeader().GetNumber(), Transactions: []BlockTransaction{}}
+
+	var validationCodes []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		validationCodes = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	for i, data := range block.GetData().GetData() {
+		transaction := BlockTransaction{}
+		envelope := &common.Envelope{}
+		payload := &common.Payload{}
+		channelHeader := &common.ChannelHeader{}
+		if proto.Unmarshal(data, envelope) == nil &&
+			proto.Unmarshal(envelope.GetPayload(), payload) == nil &&
+			proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader) == nil {
+			transaction.TxId = channelHeader.GetTxId()
+			transaction.Type = common.HeaderType(channelHeader.GetType()).String()
+		}
+		if i < len(validationCodes) {
+			transaction.ValidationCode = peer.TxValidationCode(validationCodes[i]).String()
+		}
+		event.Transactions = append(event.Transactions, transaction)
+	}
+	return event
+}
+
+// eventWriter sends events to a client over Server-Sent Events or a WebSocket.
+type eventWriter interface {
+	send(event streamEvent) error
+	keepAlive() error
+	fail(err error)
+}
+
+// streamEvents sends events to the client until the client disconnects, the gateway ends the stream,
+// or stopped is closed by the server shutting down.
+// cancel closes the gateway stream, and is called if a WebSocket client disconnects.
+func streamEvents[T any](w http.ResponseWriter, r *http.Request, stopped <-chan struct{}, cancel context.CancelFunc, events <-chan T, convert func(T) streamEvent) {
+	var writer eventWriter
+	if websocket.IsWebSocketUpgrade(r) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader has already written an HTTP error
+			slog.WarnContext(r.Context(), "WebSocket upgrade failed", "error", err)
+			return
+		}
+		defer conn.Close()
+		go discardMessages(conn, cancel)
+		writer = &webSocketWriter{conn: conn}
+	} else {
+		sse, err := newSSEWriter(w)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writer = sse
+	}
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-stopped:
+			// Clients resume from their last event on another server
+			writer.fail(errShuttingDown)
+			return
+		case event, ok := <-events:
+			if !ok {
+				if r.Context().Err() == nil {
+					writer.fail(errEventStreamClosed)
+				}
+				return
+			}
+			if err := writer.send(convert(event)); err != nil {
+				slog.WarnContext(r.Context(), "Failed to send event", "error", err)
+				return
+			}
+		case <-ticker.C:
+			if err := writer.keepAlive(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// discardMessages reads from a WebSocket, which processes control messages, until the client disconnects.
+func discardMessages(conn *websocket.Conn, cancel context.CancelFunc) {
+	defer cancel()
+	for {
+		if _, _, err := conn.NextReader(); err != nil {
+			return
+		}
+	}
+}
+
+// sseWriter writes events in the text/event-stream format.
+type sseWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, error) {
+	controller := http.NewResponseController(w)
+	// Streams outlive the server's write timeout
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	writer := &sseWriter{w: w, controller: controller}
+	// Ask browsers to reconnect after 3 seconds, resuming from the last event id
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return nil, err
+	}
+	return writer, controller.Flush()
+}
+
+func (s *sseWriter) send(event streamEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %s\ndata: %s\n\n", event.ID, data); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+func (s *sseWriter) keepAlive() error {
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	return s.controller.Flush()
+}
+
+func (s *sseWriter) fail(err error) {
+	data, _ := json.Marshal(ErrorResponse{Error: newErrorBody(err)})
+	fmt.Fprintf(s.w, "event: error\ndata: %s\n\n", data)
+	s.controller.Flush()
+}
+
+// webSocketWriter writes each event as a JSON text message.
+type webSocketWriter struct {
+	conn *websocket.Conn
+}
+
+type webSocketMessage struct {
+	ID    string     `json:"id,omitempty"`
+	Data  any        `json:"data,omitempty"`
+	Error *ErrorBody `json:"error,omitempty"`
+}
+
+func (s *webSocketWriter) send(event streamEvent) error {
+	s.conn.SetWriteDeadline(time.Now().Add(keepAliveInterval))
+	return s.conn.WriteJSON(webSocketMessage{ID: event.ID, Data: event.Data})
+}
+
+func (s *webSocketWriter) keepAlive() error {
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(keepAliveInterval))
+}
+
+func (s *webSocketWriter) fail(err error) {
+	body := newErrorBody(err)
+	s.conn.SetWriteDeadline(time.Now().Add(keepAliveInterval))
+	s.conn.WriteJSON(webSocketMessage{Error: &body})
+	s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, body.Message))
+}
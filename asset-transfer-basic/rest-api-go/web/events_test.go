@@ -0,0 +1,196 @@
+package web
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEventCheckpoints(t *testing.T) {
+	checkpoint, err := chaincodeEventCheckpoint("12:tx1")
+	if err != nil || checkpoint.BlockNumber() != 12 || checkpoint.TransactionID() != "tx1" {
+		t.Errorf("expected block 12 after tx1, got %+v (%v)", checkpoint, err)
+	}
+	if _, err := chaincodeEventCheckpoint("12"); err == nil {
+		t.Error("expected chaincode event id without transaction to be rejected")
+	}
+
+	checkpoint, err = blockEventCheckpoint("12")
+	if err != nil || checkpoint.BlockNumber() != 13 || checkpoint.TransactionID() != "" {
+		t.Errorf("expected block 13, got %+v (%v)", checkpoint, err)
+	}
+	if _, err := blockEventCheckpoint("latest"); err == nil {
+		t.Error("expected non-numeric block event id to be rejected")
+	}
+}
+
+func newTestBlock(t *testing.T, number uint64, txIDs ...string) *common.Block {
+	t.Helper()
+	block := &common.Block{
+		Header:   &common.BlockHeader{Number: number},
+		Data:     &common.BlockData{},
+		Metadata: &common.BlockMetadata{Metadata: make([][]byte, len(common.BlockMetadataIndex_name))},
+	}
+	for _, txID := range txIDs {
+		channelHeader, err := proto.Marshal(&common.ChannelHeader{Type: int32(common.HeaderType_ENDORSER_TRANSACTION), TxId: txID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := proto.Marshal(&common.Payload{Header: &common.Header{ChannelHeader: channelHeader}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		envelope, err := proto.Marshal(&common.Envelope{Payload: payload})
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.Data.Data = append(block.Data.Data, envelope)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = []byte{
+		byte(peer.TxValidationCode_VALID), byte(peer.TxValidationCode_MVCC_READ_CONFLICT),
+	}[:len(txIDs)]
+	return block
+}
+
+func TestNewBlockEvent(t *testing.T) {
+	event := newBlockEvent(newTestBlock(t, 5, "tx1", "tx2"))
+
+	if event.BlockNumber != 5 || len(event.Transactions) != 2 {
+		t.Fatalf("unexpected block event: %+v", event)
+	}
+	expected := []BlockTransaction{
+		{TxId: "tx1", Type: "ENDORSER_TRANSACTION", ValidationCode: "VALID"},
+		{TxId: "tx2", Type: "ENDORSER_TRANSACTION", ValidationCode: "MVCC_READ_CONFLICT"},
+	}
+	for i, transaction := range event.Transactions {
+		if transaction != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], transaction)
+		}
+	}
+}
+
+// newEventServer serves the events sent on the returned channel to a single client.
+func newEventServer(t *testing.T) (*httptest.Server, chan<- *common.Block) {
+	t.Helper()
+	blocks := make(chan *common.Block)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(w, r, nil, func() {}, blocks, func(block *common.Block) streamEvent {
+			event := newBlockEvent(block)
+			return streamEvent{ID: "5", Data: event}
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, blocks
+}
+
+func TestStreamEventsOverSSE(t *testing.T) {
+	server, blocks := newEventServer(t)
+
+	response, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %s", contentType)
+	}
+
+	blocks <- newTestBlock(t, 5, "tx1")
+	close(blocks)
+
+	var lines []string
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	stream := strings.Join(lines, "\n")
+	for _, expected := range []string{
+		"retry: 3000",
+		"id: 5\ndata: {\"blockNumber\":5,\"transactions\":[{\"txId\":\"tx1\",\"type\":\"ENDORSER_TRANSACTION\",\"validationCode\":\"VALID\"}]}",
+		"event: error\ndata: {\"error\":{\"code\":\"UNAVAILABLE\"",
+	} {
+		if !strings.Contains(stream, expected) {
+			t.Errorf("expected stream to contain %q, got:\n%s", expected, stream)
+		}
+	}
+}
+
+func TestStreamEventsOverWebSocket(t *testing.T) {
+	server, blocks := newEventServer(t)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	blocks <- newTestBlock(t, 5, "tx1")
+	message := struct {
+		ID   string     `json:"id"`
+		Data BlockEvent `json:"data"`
+	}{}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatal(err)
+	}
+	if message.ID != "5" || message.Data.BlockNumber != 5 || len(message.Data.Transactions) != 1 {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	close(blocks)
+	failure := webSocketMessage{}
+	if err := conn.ReadJSON(&failure); err != nil {
+		t.Fatal(err)
+	}
+	if failure.Error == nil || failure.Error.Status != http.StatusServiceUnavailable {
+		t.Errorf("expected unavailable error, got %+v", failure)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+		t.Errorf("expected close with try again later, got %v", err)
+	}
+}
+
+func TestEventHandlersRejectRequests(t *testing.T) {
+	setup := &OrgSetup{
+		ChannelID:     "mychannel",
+		ChaincodeID:   "basic",
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Authorizer:    Policy{"bob": {{Channel: "mychannel", Chaincode: "basic", Function: "ReadAsset"}}},
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testCase := range map[string]struct {
+		target      string
+		lastEventID string
+		status      int
+	}{
+		"invalid start block":   {"/events/blocks?startBlock=latest", "", http.StatusBadRequest},
+		"invalid last event id": {"/events/chaincode", "latest", http.StatusBadRequest},
+		"chaincode not allowed": {"/events/chaincode", "", http.StatusForbidden},
+		"blocks not allowed":    {"/events/blocks?lastEventId=12", "", http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			r.Header.Set(DefaultAPIKeyHeader, "secret")
+			if testCase.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", testCase.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != testCase.status {
+				t.Errorf("expected %d, got %d: %s", testCase.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
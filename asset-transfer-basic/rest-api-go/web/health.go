@@ -0,0 +1,112 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// readinessTimeout limits how long a readiness check waits for the gateway peer.
+const readinessTimeout = 5 * time.Second
+
+// metadataFunction is implemented by every chaincode written with the contract API, so evaluating it
+// checks that the gateway peer can run the chaincode without depending on the chaincode's data.
+const metadataFunction = "org.hyperledger.fabric:GetMetadata"
+
+// errShuttingDown reports that the server is draining requests before it stops.
+var errShuttingDown = &requestError{status: http.StatusServiceUnavailable, code: "UNAVAILABLE", err: errors.New("server is shutting down")}
+
+// errGatewayUnavailable is returned by readiness checks without the cause, since probes are not authenticated.
+var errGatewayUnavailable = &requestError{status: http.StatusServiceUnavailable, code: "UNAVAILABLE", err: errors.New("gateway peer is unavailable")}
+
+// lifecycle signals the shutdown of the server to readiness checks and event streams.
+type lifecycle struct {
+	draining atomic.Bool
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{stopped: make(chan struct{})}
+}
+
+// drain makes readiness checks fail, so that load balancers stop sending requests.
+func (l *lifecycle) drain() {
+	l.draining.Store(true)
+}
+
+// stop ends the event streams, which would otherwise keep their connections open for ever.
+func (l *lifecycle) stop() {
+	l.drain()
+	l.stopOnce.Do(func() { close(l.stopped) })
+}
+
+// Healthz reports that the server is running.
+func (setup *OrgSetup) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Health{Status: "ok"})
+}
+
+// Readyz reports whether the server can process transactions, by probing the gateway peer.
+func (setup *OrgSetup) Readyz(w http.ResponseWriter, r *http.Request) {
+	if setup.lifecycle.draining.Load() {
+		writeError(w, r, errShuttingDown)
+		return
+	}
+	if err := setup.probeGateway(r.Context()); err != nil {
+		slog.WarnContext(r.Context(), "Readiness check failed", "org", setup.OrgName, "error", err)
+		writeError(w, r, errGatewayUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, Health{Status: "ok"})
+}
+
+// probeGateway waits for the gRPC connection to the gateway peer, then evaluates the asset chaincode's metadata
+// as the default identity. Without a default identity or asset chaincode, only the connection is checked.
+func (setup *OrgSetup) probeGateway(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := waitForConnection(ctx, setup.connection); err != nil {
+		return err
+	}
+	if setup.DefaultIdentity == "" || setup.ChannelID == "" || setup.ChaincodeID == "" {
+		return nil
+	}
+
+	gateway, err := setup.Gateway(setup.DefaultIdentity)
+	if err != nil {
+		return err
+	}
+	contract := gateway.GetNetwork(setup.ChannelID).GetContract(setup.ChaincodeID)
+	if _, err := contract.EvaluateWithContext(ctx, metadataFunction); err != nil {
+		return fmt.Errorf("failed to evaluate %s on %s: %w", metadataFunction, setup.ChaincodeID, err)
+	}
+	return nil
+}
+
+// waitForConnection connects an idle gRPC connection and waits until it is ready.
+// It fails without waiting if the last connection attempt failed.
+func waitForConnection(ctx context.Context, connection *grpc.ClientConn) error {
+	for {
+		state := connection.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			connection.Connect()
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("gateway connection is %s", state)
+		}
+		if !connection.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("gateway connection is %s: %w", state, ctx.Err())
+		}
+	}
+}
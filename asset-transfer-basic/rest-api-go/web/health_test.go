@@ -0,0 +1,86 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newProbeHandler returns the handler of a setup whose gateway peer does not accept connections.
+func newProbeHandler(t *testing.T) (*OrgSetup, http.Handler) {
+	t.Helper()
+	connection, err := grpc.NewClient("127.0.0.1:1", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { connection.Close() })
+
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		connection:    connection,
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return setup, handler
+}
+
+func TestHealthzWithoutCredentials(t *testing.T) {
+	_, handler := newProbeHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReadyzFailsWithoutGatewayPeer(t *testing.T) {
+	_, handler := newProbeHandler(t)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	setup, handler := newProbeHandler(t)
+	setup.lifecycle.drain()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "server is shutting down") {
+		t.Errorf("expected a shutdown error, got %s", w.Body.String())
+	}
+}
+
+func TestStreamEventsEndOnShutdown(t *testing.T) {
+	l := newLifecycle()
+	done := make(chan struct{})
+	w := httptest.NewRecorder()
+	go func() {
+		defer close(done)
+		streamEvents(w, httptest.NewRequest(http.MethodGet, "/events/blocks", nil), l.stopped, func() {}, make(chan int), func(int) streamEvent {
+			return streamEvent{}
+		})
+	}()
+
+	l.stop()
+	<-done
+	if !l.draining.Load() {
+		t.Error("expected stop to drain the server")
+	}
+	if !strings.Contains(w.Body.String(), "event: error") {
+		t.Errorf("expected an error event, got %s", w.Body.String())
+	}
+}
@@ -1,11 +1,15 @@
 package web
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/x509"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
-	"path"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
@@ -13,90 +17,156 @@ import (
 	"github.com/hyperledger/fabric-gateway/pkg/identity"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"rest-api-go/wallet"
 )
 
 // Initialize the setup for the organization.
+// A single gRPC connection is shared by the gateways of all wallet identities.
 func Initialize(setup OrgSetup) (*OrgSetup, error) {
-	log.Printf("Initializing connection for %s...\n", setup.OrgName)
-	clientConnection := setup.newGrpcConnection()
-	id := setup.newIdentity()
-	sign := setup.newSign()
+	slog.Info("Initializing connection", "org", setup.OrgName)
+	if setup.Wallet == nil {
+		return nil, errors.New("no wallet configured")
+	}
+	if setup.Authenticator == nil {
+		return nil, errors.New("no authenticator configured")
+	}
+	if setup.Authorizer == nil {
+		return nil, errors.New("no authorizer configured, use AllowAll to allow every transaction")
+	}
+
+	clientConnection, err := setup.newGrpcConnection()
+	if err != nil {
+		return nil, err
+	}
+	setup.connection = clientConnection
+	setup.gateways = &gatewayCache{gateways: make(map[string]*cachedGateway)}
+
+	if setup.DefaultIdentity != "" {
+		// Fail at startup rather than on the first request if the default identity is unusable
+		if _, err := setup.Gateway(setup.DefaultIdentity); err != nil {
+			clientConnection.Close()
+			return nil, err
+		}
+	}
+	slog.Info("Initialization complete", "org", setup.OrgName)
+	return &setup, nil
+}
+
+// gatewayCache holds one gateway per wallet identity.
+type gatewayCache struct {
+	mu       sync.Mutex
+	gateways map[string]*cachedGateway
+}
+
+// cachedGateway is the gateway of a wallet label, connected for the certificate the label held at the time.
+type cachedGateway struct {
+	certificateHash [sha256.Size]byte
+	gateway         *client.Gateway
+}
+
+// Gateway returns the gateway that signs as the wallet identity with the given label,
+// connecting it on first use. The identity is read from the wallet on every call, so that a label whose
+// certificate is replaced gets a new gateway and a label removed from the wallet stops working.
+// The error matches wallet.ErrNotFound for unknown labels and wallet.ErrInvalidLabel for invalid ones.
+func (setup *OrgSetup) Gateway(label string) (*client.Gateway, error) {
+	id, sign, err := setup.Wallet.Credentials(label)
+	if errors.Is(err, wallet.ErrNotFound) {
+		setup.gateways.remove(label)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if id.MspID() != setup.MSPID {
+		return nil, fmt.Errorf("identity %s belongs to %s, not %s", label, id.MspID(), setup.MSPID)
+	}
+	certificateHash := sha256.Sum256(id.Credentials())
+
+	setup.gateways.mu.Lock()
+	defer setup.gateways.mu.Unlock()
+
+	cached, ok := setup.gateways.gateways[label]
+	if ok && cached.certificateHash == certificateHash {
+		return cached.gateway, nil
+	}
 
 	gateway, err := client.Connect(
 		id,
 		client.WithSign(sign),
 		client.WithHash(hash.SHA256),
-		client.WithClientConnection(clientConnection),
+		client.WithClientConnection(setup.connection),
 		client.WithEvaluateTimeout(5*time.Second),
 		client.WithEndorseTimeout(15*time.Second),
 		client.WithSubmitTimeout(5*time.Second),
 		client.WithCommitStatusTimeout(1*time.Minute),
 	)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to connect gateway for identity %s: %w", label, err)
 	}
-	setup.Gateway = *gateway
-	log.Println("Initialization complete")
-	return &setup, nil
-}
-
-// newGrpcConnection creates a gRPC connection to the Gateway server.
-func (setup OrgSetup) newGrpcConnection() *grpc.ClientConn {
-	certificate, err := loadCertificate(setup.TLSCertPath)
-	if err != nil {
-		panic(err)
+	if ok {
+		// The shared connection stays open for requests still using the replaced gateway
+		cached.gateway.Close()
 	}
+	setup.gateways.gateways[label] = &cachedGateway{certificateHash: certificateHash, gateway: gateway}
+	return gateway, nil
+}
 
-	certPool := x509.NewCertPool()
-	certPool.AddCert(certificate)
-	transportCredentials := credentials.NewClientTLSFromCert(certPool, setup.GatewayPeer)
+// remove closes and forgets the gateway of a wallet label.
+func (c *gatewayCache) remove(label string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	connection, err := grpc.NewClient(setup.PeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
-	if err != nil {
-		panic(fmt.Errorf("failed to create gRPC connection: %w", err))
+	if cached, ok := c.gateways[label]; ok {
+		cached.gateway.Close()
+		delete(c.gateways, label)
 	}
-
-	return connection
 }
 
-// newIdentity creates a client identity for this Gateway connection using an X.509 certificate.
-func (setup OrgSetup) newIdentity() *identity.X509Identity {
-	certificate, err := loadCertificate(setup.CertPath)
-	if err != nil {
-		panic(err)
-	}
+// Close closes the gateways of all wallet identities and the gRPC connection they share.
+func (setup *OrgSetup) Close() error {
+	setup.gateways.mu.Lock()
+	defer setup.gateways.mu.Unlock()
 
-	id, err := identity.NewX509Identity(setup.MSPID, certificate)
-	if err != nil {
-		panic(err)
+	for label, cached := range setup.gateways.gateways {
+		cached.gateway.Close()
+		delete(setup.gateways.gateways, label)
 	}
-
-	return id
+	return setup.connection.Close()
 }
 
-// newSign creates a function that generates a digital signature from a message digest using a private key.
-func (setup OrgSetup) newSign() identity.Sign {
-	files, err := os.ReadDir(setup.KeyPath)
-	if err != nil {
-		panic(fmt.Errorf("failed to read private key directory: %w", err))
+// requestGateway returns the gateway for the identity chosen by the request's principal.
+func (setup *OrgSetup) requestGateway(ctx context.Context) (*client.Gateway, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Identity == "" {
+		return nil, &requestError{status: http.StatusUnauthorized, code: "UNAUTHENTICATED", err: errors.New("no identity selected")}
+	}
+	gateway, err := setup.Gateway(principal.Identity)
+	if errors.Is(err, wallet.ErrNotFound) || errors.Is(err, wallet.ErrInvalidLabel) {
+		return nil, &requestError{status: http.StatusForbidden, code: "PERMISSION_DENIED", err: fmt.Errorf("identity %s is not available", principal.Identity)}
 	}
-	privateKeyPEM, err := os.ReadFile(path.Join(setup.KeyPath, files[0].Name()))
-
 	if err != nil {
-		panic(fmt.Errorf("failed to read private key file: %w", err))
+		slog.ErrorContext(ctx, "Failed to load identity", "identity", principal.Identity, "error", err)
+		return nil, &requestError{status: http.StatusInternalServerError, code: "INTERNAL", err: fmt.Errorf("error loading identity %s", principal.Identity)}
 	}
+	return gateway, nil
+}
 
-	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+// newGrpcConnection creates a gRPC connection to the Gateway server.
+func (setup OrgSetup) newGrpcConnection() (*grpc.ClientConn, error) {
+	certificate, err := loadCertificate(setup.TLSCertPath)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
-	sign, err := identity.NewPrivateKeySign(privateKey)
+	certPool := x509.NewCertPool()
+	certPool.AddCert(certificate)
+	transportCredentials := credentials.NewClientTLSFromCert(certPool, setup.GatewayPeer)
+
+	connection, err := grpc.NewClient(setup.PeerEndpoint, grpc.WithTransportCredentials(transportCredentials))
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
 	}
 
-	return sign
+	return connection, nil
 }
 
 func loadCertificate(filename string) (*x509.Certificate, error) {
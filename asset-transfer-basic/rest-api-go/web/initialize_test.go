@@ -0,0 +1,69 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"rest-api-go/wallet"
+)
+
+func TestGatewayFollowsWallet(t *testing.T) {
+	setup, _ := newFakeGatewaySetup(t)
+	first, err := setup.Gateway("User1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gateway, err := setup.Gateway("User1"); err != nil || gateway != first {
+		t.Errorf("expected the cached gateway, got %v (%v)", gateway, err)
+	}
+
+	// A replaced certificate gets a gateway of its own
+	if err := setup.Wallet.Put("User1", newWalletIdentity(t, "User1")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := setup.Gateway("User1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first || string(second.Identity().Credentials()) == string(first.Identity().Credentials()) {
+		t.Error("expected a new gateway for the replaced certificate")
+	}
+
+	if err := setup.Wallet.Remove("User1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := setup.Gateway("User1"); err == nil {
+		t.Error("expected an error for the removed identity")
+	}
+	if _, ok := setup.gateways.gateways["User1"]; ok {
+		t.Error("expected the gateway of the removed identity to be forgotten")
+	}
+}
+
+func TestHandlerRejectsUnavailableIdentities(t *testing.T) {
+	setup, _ := newFakeGatewaySetup(t)
+	identities, err := wallet.NewFileSystem(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup.Wallet = identities
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, label := range []string{"Unknown", "../User1"} {
+		t.Run(label, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/query", strings.NewReader(`{"channelId":"mychannel","chaincodeId":"basic","function":"GetAllAssets"}`))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set(DefaultIdentityHeader, label)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("expected 403, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
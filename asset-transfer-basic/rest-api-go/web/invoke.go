@@ -1,40 +1,196 @@
 package web
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Invoke handles chaincode invoke requests.
-func (setup *OrgSetup) Invoke(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("Received Invoke request")
-	if err := r.ParseForm(); err != nil {
-		fmt.Fprintf(w, "ParseForm() err: %s", err)
-		return
+// Invoke submits the transaction function named in the JSON or form request body.
+// It waits for the transaction to commit and responds with its block number and validation code,
+// or with async=true responds once the transaction is submitted.
+func (setup *OrgSetup) Invoke(ctx context.Context, request InvokeRequestObject) (InvokeResponseObject, error) {
+	var channelID, chaincodeID, function string
+	var args []string
+	var options []client.ProposalOption
+	switch {
+	case request.JSONBody != nil:
+		body := request.JSONBody
+		channelID, chaincodeID, function, args = body.ChannelId, body.ChaincodeId, body.Function, body.Args
+		options = proposalOptions(*body)
+	case request.FormdataBody != nil:
+		body := request.FormdataBody
+		channelID, chaincodeID, function, args = body.Channelid, body.Chaincodeid, body.Function, body.Args
+		options = proposalOptions(TransactionRequest{EndorsingOrgs: body.EndorsingOrgs})
+	default:
+		return nil, badRequest("request body is required")
 	}
-	chainCodeName := r.FormValue("chaincodeid")
-	channelID := r.FormValue("channelid")
-	function := r.FormValue("function")
-	args := r.Form["args"]
-	fmt.Printf("channel: %s, chaincode: %s, function: %s, args: %s\n", channelID, chainCodeName, function, args)
-	network := setup.Gateway.GetNetwork(channelID)
-	contract := network.GetContract(chainCodeName)
-	txn_proposal, err := contract.NewProposal(function, client.WithArguments(args...))
+
+	if request.Params.Async {
+		status, err := setup.submitAsync(ctx, channelID, chaincodeID, function, args, options...)
+		if err != nil {
+			return nil, err
+		}
+		return Invoke202JSONResponse{TransactionAcceptedJSONResponse{
+			Body:    status,
+			Headers: TransactionAcceptedResponseHeaders{Location: resourcePath(ctx, "/transactions/"+status.TxId)},
+		}}, nil
+	}
+
+	response, err := setup.submit(ctx, channelID, chaincodeID, function, args, options...)
+	if err != nil {
+		return nil, err
+	}
+	return Invoke200JSONResponse{TransactionSuccessJSONResponse(*response)}, nil
+}
+
+// transactionFormFields are the parameters of a TransactionForm.
+var transactionFormFields = map[string]bool{"channelid": true, "chaincodeid": true, "function": true, "args": true, "endorsingOrgs": true}
+
+// rejectUnknownFormFields rejects form-encoded invoke requests with parameters that a TransactionForm does not have,
+// such as transient data, which the form decoder would otherwise drop without notice.
+func rejectUnknownFormFields(f StrictHandlerFunc, operationID string) StrictHandlerFunc {
+	if operationID != "Invoke" {
+		return f
+	}
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		if invoke, ok := request.(InvokeRequestObject); ok && invoke.FormdataBody != nil {
+			for field := range r.PostForm {
+				if !transactionFormFields[field] {
+					return nil, badRequest("form parameter %s is not supported, send a JSON body instead", field)
+				}
+			}
+		}
+		return f(ctx, w, r, request)
+	}
+}
+
+// proposalOptions returns the options that pass the transient data of a request to the chaincode
+// and select the organizations that endorse it.
+func proposalOptions(request TransactionRequest) []client.ProposalOption {
+	var options []client.ProposalOption
+	if len(request.Transient) > 0 {
+		options = append(options, client.WithTransient(request.Transient))
+	}
+	if len(request.EndorsingOrgs) > 0 {
+		options = append(options, client.WithEndorsingOrganizations(request.EndorsingOrgs...))
+	}
+	return options
+}
+
+// submit submits a transaction as the identity of the request's principal and waits for it to commit.
+// A transaction that commits with a validation code other than VALID fails with a commitError.
+func (setup *OrgSetup) submit(ctx context.Context, channelID, chaincodeID, function string, args []string, options ...client.ProposalOption) (*TransactionResponse, error) {
+	txn_committed, result, err := setup.submitTransaction(ctx, channelID, chaincodeID, function, args, options...)
 	if err != nil {
-		fmt.Fprintf(w, "Error creating txn proposal: %s", err)
-		return
+		return nil, err
 	}
-	txn_endorsed, err := txn_proposal.Endorse()
+	labels := transactionLabels{channelID: channelID, chaincodeID: chaincodeID, function: function}
+	status, err := setup.awaitCommit(ctx, labels, func(ctx context.Context) (*client.Status, error) {
+		return txn_committed.StatusWithContext(ctx)
+	})
 	if err != nil {
-		fmt.Fprintf(w, "Error endorsing txn: %s", err)
-		return
+		return nil, err
+	}
+	if !status.Successful {
+		return nil, &commitError{status: status}
 	}
-	txn_committed, err := txn_endorsed.Submit()
+	return &TransactionResponse{
+		TxId:           status.TransactionID,
+		Result:         chaincodeResult(result),
+		BlockNumber:    status.BlockNumber,
+		ValidationCode: status.Code.String(),
+	}, nil
+}
+
+// submitAsync submits a transaction as the identity of the request's principal without waiting for it to commit.
+// The commit status is tracked in the background and reported by GetTransaction.
+func (setup *OrgSetup) submitAsync(ctx context.Context, channelID, chaincodeID, function string, args []string, options ...client.ProposalOption) (TransactionStatus, error) {
+	txn_committed, result, err := setup.submitTransaction(ctx, channelID, chaincodeID, function, args, options...)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	labels := transactionLabels{channelID: channelID, chaincodeID: chaincodeID, function: function}
+	// Keep the trace of the request, but not its cancellation, since the request context ends with the response
+	ctx = context.WithoutCancel(ctx)
+	status := func() (*client.Status, error) {
+		return setup.awaitCommit(ctx, labels, func(context.Context) (*client.Status, error) {
+			// Wait with the gateway's commit status timeout
+			return txn_committed.Status()
+		})
+	}
+	return setup.transactions.track(principal.Name, txn_committed.TransactionID(), result, status), nil
+}
+
+// submitTransaction endorses a transaction and submits it to the orderer, returning the chaincode result.
+func (setup *OrgSetup) submitTransaction(ctx context.Context, channelID, chaincodeID, function string, args []string, options ...client.ProposalOption) (*client.Commit, []byte, error) {
+	slog.InfoContext(ctx, "Received Invoke request", "channel", channelID, "chaincode", chaincodeID, "function", function, "args", args)
+	if err := setup.authorize(ctx, channelID, chaincodeID, function); err != nil {
+		return nil, nil, err
+	}
+	gateway, err := setup.requestGateway(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	if err := setup.Limiter.admit(ctx, principal.Name, args); err != nil {
+		return nil, nil, err
+	}
+	network := gateway.GetNetwork(channelID)
+	contract := network.GetContract(chaincodeID)
+	options = append([]client.ProposalOption{client.WithArguments(args...)}, options...)
+	txn_proposal, err := contract.NewProposal(function, options...)
+	if err != nil {
+		return nil, nil, badRequest("error creating txn proposal: %s", err)
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("fabric.tx_id", txn_proposal.TransactionID()))
+	labels := transactionLabels{channelID: channelID, chaincodeID: chaincodeID, function: function}
+
+	release, err := setup.Limiter.acquireEndorsement(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var txn_endorsed *client.Transaction
+	err = setup.observe(ctx, stageEndorse, labels, func(ctx context.Context) (err error) {
+		txn_endorsed, err = txn_proposal.EndorseWithContext(ctx)
+		return err
+	})
+	release()
+	if err != nil {
+		return nil, nil, err
+	}
+	var txn_committed *client.Commit
+	err = setup.observe(ctx, stageSubmit, labels, func(ctx context.Context) (err error) {
+		txn_committed, err = txn_endorsed.SubmitWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return txn_committed, txn_endorsed.Result(), nil
+}
+
+// awaitCommit gets the commit status of a transaction, and counts its validation code.
+func (setup *OrgSetup) awaitCommit(ctx context.Context, labels transactionLabels, commitStatus func(ctx context.Context) (*client.Status, error)) (*client.Status, error) {
+	var status *client.Status
+	err := setup.observe(ctx, stageCommit, labels, func(ctx context.Context) (err error) {
+		status, err = commitStatus(ctx)
+		if err == nil {
+			trace.SpanFromContext(ctx).SetAttributes(
+				attribute.String("fabric.validation_code", status.Code.String()),
+				attribute.Int64("fabric.block_number", int64(status.BlockNumber)),
+			)
+		}
+		return err
+	})
 	if err != nil {
-		fmt.Fprintf(w, "Error submitting transaction: %s", err)
-		return
+		return nil, err
 	}
-	fmt.Fprintf(w, "Transaction ID : %s Response: %s", txn_committed.TransactionID(), txn_endorsed.Result())
+	setup.Metrics.committed(setup.OrgName, setup.metricLabels(labels), status.Code.String())
+	return status, nil
 }
@@ -0,0 +1,210 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"rest-api-go/wallet"
+)
+
+// fakeGateway records the proposals it receives. Evaluate succeeds and Endorse fails, so that invoke stops
+// before submitting. CommitStatus knows only tx1.
+type fakeGateway struct {
+	gateway.UnimplementedGatewayServer
+	mu        sync.Mutex
+	proposals []*gateway.EndorseRequest
+}
+
+func (g *fakeGateway) Evaluate(_ context.Context, request *gateway.EvaluateRequest) (*gateway.EvaluateResponse, error) {
+	g.record(&gateway.EndorseRequest{ProposedTransaction: request.ProposedTransaction, EndorsingOrganizations: request.TargetOrganizations})
+	return &gateway.EvaluateResponse{Result: &peer.Response{Status: 200, Payload: []byte("ok")}}, nil
+}
+
+func (g *fakeGateway) Endorse(_ context.Context, request *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	g.record(request)
+	return nil, status.Error(codes.Unavailable, "no endorsing peers")
+}
+
+// CommitStatus reports tx1 as committed in block 5 and any other transaction as not committed before the deadline.
+func (g *fakeGateway) CommitStatus(_ context.Context, request *gateway.SignedCommitStatusRequest) (*gateway.CommitStatusResponse, error) {
+	commitRequest := &gateway.CommitStatusRequest{}
+	if err := proto.Unmarshal(request.Request, commitRequest); err != nil {
+		return nil, err
+	}
+	if commitRequest.TransactionId != "tx1" {
+		return nil, status.Error(codes.DeadlineExceeded, "context deadline exceeded")
+	}
+	return &gateway.CommitStatusResponse{Result: peer.TxValidationCode_VALID, BlockNumber: 5}, nil
+}
+
+func (g *fakeGateway) record(request *gateway.EndorseRequest) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.proposals = append(g.proposals, request)
+}
+
+// lastProposal returns the transient data and the endorsing organizations of the last proposal.
+func (g *fakeGateway) lastProposal(t *testing.T) (map[string][]byte, []string) {
+	t.Helper()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.proposals) == 0 {
+		t.Fatal("expected a proposal")
+	}
+	request := g.proposals[len(g.proposals)-1]
+
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(request.ProposedTransaction.ProposalBytes, proposal); err != nil {
+		t.Fatal(err)
+	}
+	payload := &peer.ChaincodeProposalPayload{}
+	if err := proto.Unmarshal(proposal.Payload, payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload.TransientMap, request.EndorsingOrganizations
+}
+
+// newWalletIdentity returns a self-signed Org1MSP identity with the given common name.
+func newWalletIdentity(t *testing.T, name string) *wallet.Identity {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: newSerial(), Subject: pkix.Name{CommonName: name}}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &wallet.Identity{
+		MSPID:       "Org1MSP",
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		PrivateKey:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// newFakeGatewaySetup returns a setup whose default identity User1 is connected to a fakeGateway.
+func newFakeGatewaySetup(t *testing.T) (*OrgSetup, *fakeGateway) {
+	t.Helper()
+	fake := &fakeGateway{}
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	gateway.RegisterGatewayServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	connection, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { connection.Close() })
+
+	identities := wallet.NewInMemory()
+	if err := identities.Put("User1", newWalletIdentity(t, "User1")); err != nil {
+		t.Fatal(err)
+	}
+
+	setup := &OrgSetup{
+		MSPID:           "Org1MSP",
+		DefaultIdentity: "User1",
+		Wallet:          identities,
+		Authenticator:   IdentityHeader{},
+		Authorizer:      AllowAll{},
+		connection:      connection,
+		gateways:        &gatewayCache{gateways: make(map[string]*cachedGateway)},
+	}
+	return setup, fake
+}
+
+func TestProposalOptionsReachGateway(t *testing.T) {
+	setup, fake := newFakeGatewaySetup(t)
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// asset_properties is base64 for {"ID":"asset1"}
+	body := `{"channelId":"mychannel","chaincodeId":"private","function":"CreateAsset","transient":{"asset_properties":"eyJJRCI6ImFzc2V0MSJ9"},"endorsingOrgs":["Org1MSP","Org2MSP"]}`
+	for _, target := range []string{"/invoke", "/query"} {
+		t.Run(target, func(t *testing.T) {
+			fake.proposals = nil
+			r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code == http.StatusBadRequest {
+				t.Fatalf("expected request to reach the gateway, got %d: %s", w.Code, w.Body.String())
+			}
+
+			transient, endorsingOrgs := fake.lastProposal(t)
+			if string(transient["asset_properties"]) != `{"ID":"asset1"}` {
+				t.Errorf("expected transient asset_properties in proposal, got %q", transient)
+			}
+			if !slices.Equal(endorsingOrgs, []string{"Org1MSP", "Org2MSP"}) {
+				t.Errorf("expected endorsing orgs Org1MSP and Org2MSP, got %v", endorsingOrgs)
+			}
+		})
+	}
+}
+
+func TestFormInvokeProposalOptions(t *testing.T) {
+	setup, fake := newFakeGatewaySetup(t)
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := func(form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/invoke", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	form := url.Values{"channelid": {"mychannel"}, "chaincodeid": {"basic"}, "function": {"CreateAsset"}}
+
+	form["endorsingOrgs"] = []string{"Org1MSP", "Org2MSP"}
+	if w := post(form); w.Code == http.StatusBadRequest {
+		t.Fatalf("expected request to reach the gateway, got %d: %s", w.Code, w.Body.String())
+	}
+	if _, endorsingOrgs := fake.lastProposal(t); !slices.Equal(endorsingOrgs, []string{"Org1MSP", "Org2MSP"}) {
+		t.Errorf("expected endorsing orgs Org1MSP and Org2MSP, got %v", endorsingOrgs)
+	}
+
+	// Transient data is not silently dropped
+	fake.proposals = nil
+	form.Set("transient", "eyJJRCI6ImFzc2V0MSJ9")
+	if w := post(form); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(fake.proposals) != 0 {
+		t.Error("expected the rejected request not to reach the gateway")
+	}
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultIdentityClaim is the JWT claim that selects the signing identity by wallet label.
+const DefaultIdentityClaim = "fabric_identity"
+
+// JWT authenticates requests by a bearer token in the Authorization header, verified locally with an
+// HS256 secret or an RS256 public key. The principal name is the token's subject.
+type JWT struct {
+	HMACSecret   []byte         // Verifies HS256 tokens if set
+	RSAPublicKey *rsa.PublicKey // Verifies RS256 tokens if set
+	Issuer       string         // Required iss claim, if set
+	Audience     string         // Required aud claim, if set
+	// IdentityClaim names the claim holding the wallet label. Defaults to DefaultIdentityClaim.
+	// Tokens without the claim transact as OrgSetup.DefaultIdentity.
+	IdentityClaim string
+	// QueryParameter, if set, also accepts the token in this query parameter of GET requests,
+	// for browser EventSource and WebSocket clients, which cannot set the Authorization header.
+	QueryParameter string
+}
+
+// Authenticate implements Authenticator.
+func (a *JWT) Authenticate(r *http.Request) (*Principal, error) {
+	token, err := a.token(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var methods []string
+	if a.HMACSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if a.RSAPublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if a.Issuer != "" {
+		options = append(options, jwt.WithIssuer(a.Issuer))
+	}
+	if a.Audience != "" {
+		options = append(options, jwt.WithAudience(a.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, a.key, options...); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, errors.New("invalid token: no subject")
+	}
+	identityClaim := a.IdentityClaim
+	if identityClaim == "" {
+		identityClaim = DefaultIdentityClaim
+	}
+	identity, _ := claims[identityClaim].(string)
+	return &Principal{Name: subject, Identity: identity}, nil
+}
+
+// token returns the bearer token from the Authorization header, or from the query parameter if allowed.
+func (a *JWT) token(r *http.Request) (string, error) {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", ErrNoCredentials
+		}
+		return token, nil
+	}
+	if a.QueryParameter != "" && r.Method == http.MethodGet {
+		if token := r.URL.Query().Get(a.QueryParameter); token != "" {
+			return token, nil
+		}
+	}
+	return "", ErrNoCredentials
+}
+
+// key returns the verification key for the token's signing method, which the parser has already checked is allowed.
+func (a *JWT) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return a.HMACSecret, nil
+	case *jwt.SigningMethodRSA:
+		return a.RSAPublicKey, nil
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
@@ -0,0 +1,327 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// limiterRetention is how long the rate limiter of an idle principal is kept after its bucket refills.
+const limiterRetention = time.Minute
+
+// Limits protects the gateway peer from clients that send too many or too large transactions.
+// A zero field disables its limit.
+type Limits struct {
+	Rate        rate.Limit // Transactions per second of each principal
+	Burst       int        // Transactions each principal may send at once, at least 1 if Rate is set
+	GlobalRate  rate.Limit // Transactions per second of all principals together
+	GlobalBurst int        // Transactions all principals may send at once, at least 1 if GlobalRate is set
+
+	MaxArgs      int   // Chaincode function arguments per transaction
+	MaxBodyBytes int64 // Size of a request body
+
+	// MaxConcurrentEndorsements is the number of transactions endorsed at once. Other transactions wait for up to
+	// EndorsementWait for their turn.
+	MaxConcurrentEndorsements int
+	EndorsementWait           time.Duration
+
+	MaxBatchSize        int // Transactions per batch
+	MaxBatchParallelism int // Transactions of a batch submitted at once
+	// BatchTimeout is how long a batch may take to start its transactions. Transactions that have not started by
+	// then are skipped. The response of a batch may take BatchTimeout plus batchCompletionTime, beyond the server's
+	// WriteTimeout, and has no deadline if BatchTimeout is zero.
+	BatchTimeout time.Duration
+}
+
+// DefaultLimits suit a single gateway peer.
+var DefaultLimits = Limits{
+	Rate:                      10,
+	Burst:                     20,
+	GlobalRate:                100,
+	GlobalBurst:               200,
+	MaxArgs:                   100,
+	MaxBodyBytes:              1 << 20,
+	MaxConcurrentEndorsements: 32,
+	EndorsementWait:           time.Second,
+	MaxBatchSize:              1000,
+	MaxBatchParallelism:       32,
+	BatchTimeout:              10 * time.Minute,
+}
+
+// batchCompletionTime is how long the transactions of a batch that are still running at its BatchTimeout may take to
+// commit. It covers the endorse, submit and commit status timeouts of the gateway.
+const batchCompletionTime = 2 * time.Minute
+
+// defaultBatchParallelism is the number of transactions of a batch submitted at once, unless the request asks for
+// another.
+const defaultBatchParallelism = 8
+
+// Limiter enforces Limits. It is safe to share between organizations, so that the limits apply to all of them.
+type Limiter struct {
+	limits       Limits
+	global       *rate.Limiter
+	endorsements chan struct{}
+
+	mu         sync.Mutex
+	principals map[string]*principalLimiter
+	lastPrune  time.Time
+}
+
+type principalLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// NewLimiter creates a Limiter that enforces the given limits.
+func NewLimiter(limits Limits) *Limiter {
+	l := &Limiter{limits: limits, principals: make(map[string]*principalLimiter)}
+	if limits.GlobalRate > 0 {
+		l.global = rate.NewLimiter(limits.GlobalRate, max(limits.GlobalBurst, 1))
+	}
+	if limits.MaxConcurrentEndorsements > 0 {
+		l.endorsements = make(chan struct{}, limits.MaxConcurrentEndorsements)
+	}
+	return l
+}
+
+// rateLimitError rejects a request that exceeds a rate or concurrency limit, which the client may retry after a delay.
+type rateLimitError struct {
+	retryAfter time.Duration
+	err        *requestError
+}
+
+func newRateLimitError(retryAfter time.Duration, format string, args ...any) *rateLimitError {
+	return &rateLimitError{
+		retryAfter: retryAfter,
+		err:        &requestError{status: http.StatusTooManyRequests, code: "RESOURCE_EXHAUSTED", err: fmt.Errorf(format, args...)},
+	}
+}
+
+func (e *rateLimitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return e.err
+}
+
+// retryAfterSeconds returns the value of the Retry-After header, in whole seconds of at least 1.
+func (e *rateLimitError) retryAfterSeconds() string {
+	return strconv.Itoa(max(int(math.Ceil(e.retryAfter.Seconds())), 1))
+}
+
+// allow takes a token for a transaction of the principal from its bucket and from the global bucket.
+// A nil Limiter allows every transaction.
+func (l *Limiter) allow(principal string, args []string) error {
+	if l == nil {
+		return nil
+	}
+	if l.limits.MaxArgs > 0 && len(args) > l.limits.MaxArgs {
+		return badRequest("%d arguments exceed the limit of %d", len(args), l.limits.MaxArgs)
+	}
+
+	now := time.Now()
+	var reservation *rate.Reservation
+	if limiter := l.principalLimiter(principal, now); limiter != nil {
+		reservation = limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return newRateLimitError(delay, "rate limit of %s exceeded", principal)
+		}
+	}
+	if l.global != nil {
+		global := l.global.ReserveN(now, 1)
+		if delay := global.DelayFrom(now); delay > 0 {
+			global.CancelAt(now)
+			if reservation != nil {
+				// Give the principal's token back, since the transaction was not sent
+				reservation.CancelAt(now)
+			}
+			return newRateLimitError(delay, "server rate limit exceeded")
+		}
+	}
+	return nil
+}
+
+// wait takes a token for a transaction like allow, but waits for the tokens rather than failing if the context allows.
+func (l *Limiter) wait(ctx context.Context, principal string, args []string) error {
+	if l == nil {
+		return nil
+	}
+	if l.limits.MaxArgs > 0 && len(args) > l.limits.MaxArgs {
+		return badRequest("%d arguments exceed the limit of %d", len(args), l.limits.MaxArgs)
+	}
+
+	now := time.Now()
+	var reservations []*rate.Reservation
+	if limiter := l.principalLimiter(principal, now); limiter != nil {
+		reservations = append(reservations, limiter.ReserveN(now, 1))
+	}
+	if l.global != nil {
+		reservations = append(reservations, l.global.ReserveN(now, 1))
+	}
+	var delay time.Duration
+	for _, reservation := range reservations {
+		delay = max(delay, reservation.DelayFrom(now))
+	}
+	if delay <= 0 {
+		return nil
+	}
+	cancel := func() {
+		for _, reservation := range reservations {
+			reservation.Cancel()
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		cancel()
+		return newRateLimitError(delay, "rate limit of %s exceeded", principal)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// waitForRateKey marks the context of a transaction that waits for its rate limit tokens, see Limiter.admit.
+type waitForRateKey struct{}
+
+// admit takes a token for a transaction with wait if the context is marked by waitForRateKey, and with allow
+// otherwise.
+func (l *Limiter) admit(ctx context.Context, principal string, args []string) error {
+	if waitForRate, _ := ctx.Value(waitForRateKey{}).(bool); waitForRate {
+		return l.wait(ctx, principal, args)
+	}
+	return l.allow(principal, args)
+}
+
+// principalLimiter returns the rate limiter of a principal, or nil if principals are not rate limited.
+func (l *Limiter) principalLimiter(principal string, now time.Time) *rate.Limiter {
+	if l.limits.Rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastPrune) > limiterRetention {
+		l.prune(now)
+	}
+	entry, ok := l.principals[principal]
+	if !ok {
+		entry = &principalLimiter{limiter: rate.NewLimiter(l.limits.Rate, max(l.limits.Burst, 1))}
+		l.principals[principal] = entry
+	}
+	entry.lastUsed = now
+	return entry.limiter
+}
+
+// prune forgets the rate limiters of principals whose buckets have refilled and stayed unused for limiterRetention,
+// since a new limiter would allow them the same.
+func (l *Limiter) prune(now time.Time) {
+	refill := time.Duration(float64(max(l.limits.Burst, 1)) / float64(l.limits.Rate) * float64(time.Second))
+	for principal, entry := range l.principals {
+		if now.Sub(entry.lastUsed) > refill+limiterRetention {
+			delete(l.principals, principal)
+		}
+	}
+	l.lastPrune = now
+}
+
+// acquireEndorsement waits for one of the concurrent endorsements, and returns the function that releases it.
+func (l *Limiter) acquireEndorsement(ctx context.Context) (func(), error) {
+	if l == nil || l.endorsements == nil {
+		return func() {}, nil
+	}
+	release := func() { <-l.endorsements }
+
+	select {
+	case l.endorsements <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if l.limits.EndorsementWait <= 0 {
+		return nil, newRateLimitError(time.Second, "too many concurrent endorsements")
+	}
+
+	timer := time.NewTimer(l.limits.EndorsementWait)
+	defer timer.Stop()
+	select {
+	case l.endorsements <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, newRateLimitError(l.limits.EndorsementWait, "too many concurrent endorsements")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// allowBatch rejects batches of more than MaxBatchSize transactions.
+func (l *Limiter) allowBatch(size int) error {
+	if l == nil || l.limits.MaxBatchSize <= 0 || size <= l.limits.MaxBatchSize {
+		return nil
+	}
+	return badRequest("%d transactions exceed the batch limit of %d", size, l.limits.MaxBatchSize)
+}
+
+// batchParallelism returns the number of transactions of a batch to submit at once, given the number requested,
+// if any, and MaxBatchParallelism.
+func (l *Limiter) batchParallelism(requested int) int {
+	parallelism := defaultBatchParallelism
+	if requested > 0 {
+		parallelism = requested
+	}
+	if l != nil && l.limits.MaxBatchParallelism > 0 {
+		parallelism = min(parallelism, l.limits.MaxBatchParallelism)
+	}
+	return parallelism
+}
+
+// batchTimeout returns BatchTimeout, or zero if batches may take any time.
+func (l *Limiter) batchTimeout() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.limits.BatchTimeout
+}
+
+// limitBody rejects request bodies larger than MaxBodyBytes with 413.
+func (l *Limiter) limitBody(next http.Handler) http.Handler {
+	if l == nil || l.limits.MaxBodyBytes <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > l.limits.MaxBodyBytes {
+			writeError(w, r, bodyTooLarge(l.limits.MaxBodyBytes))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, l.limits.MaxBodyBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bodyTooLarge(limit int64) error {
+	return &requestError{status: http.StatusRequestEntityTooLarge, code: "PAYLOAD_TOO_LARGE", err: fmt.Errorf("request body exceeds %d bytes", limit)}
+}
+
+// requestBodyError reports a request body that could not be read because it exceeds the size limit with 413,
+// and other errors with 400.
+func requestBodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return bodyTooLarge(maxBytesErr.Limit)
+	}
+	return badRequest("%s", err)
+}
@@ -0,0 +1,169 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimiterRateLimitsPrincipals(t *testing.T) {
+	limiter := NewLimiter(Limits{Rate: 1, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.allow("alice", nil); err != nil {
+			t.Fatalf("expected burst of 2 to be allowed, got %s", err)
+		}
+	}
+	var rateLimitErr *rateLimitError
+	if err := limiter.allow("alice", nil); !errors.As(err, &rateLimitErr) || rateLimitErr.retryAfter <= 0 {
+		t.Fatalf("expected rate limit error with a delay, got %v", err)
+	}
+	if body := newErrorBody(rateLimitErr); body.Status != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", body.Status)
+	}
+	if err := limiter.allow("bob", nil); err != nil {
+		t.Errorf("expected other principal to be allowed, got %s", err)
+	}
+}
+
+func TestLimiterRateLimitsAllPrincipals(t *testing.T) {
+	limiter := NewLimiter(Limits{Rate: 1, Burst: 1, GlobalRate: 1, GlobalBurst: 1})
+
+	if err := limiter.allow("alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	var rateLimitErr *rateLimitError
+	if err := limiter.allow("bob", nil); !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected global rate limit error, got %v", err)
+	}
+	// The rejected transaction must not use up bob's own bucket
+	if tokens := limiter.principals["bob"].limiter.Tokens(); tokens < 0.99 {
+		t.Errorf("expected bob's token to be returned, got %f tokens", tokens)
+	}
+}
+
+func TestLimiterWaitsForRate(t *testing.T) {
+	limiter := NewLimiter(Limits{Rate: 10, Burst: 1})
+
+	if err := limiter.wait(context.Background(), "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := limiter.wait(context.Background(), "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited < 80*time.Millisecond {
+		t.Errorf("expected to wait for the next token, waited %s", waited)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	var rateLimitErr *rateLimitError
+	if err := limiter.wait(ctx, "alice", nil); !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected rate limit error when the token comes after the deadline, got %v", err)
+	}
+	// The token of the failed transaction must be returned
+	if err := limiter.wait(context.Background(), "alice", nil); err != nil {
+		t.Fatal(err)
+	}
+	if waited := time.Since(start); waited > 250*time.Millisecond {
+		t.Errorf("expected the failed transaction's token to be returned, waited %s", waited)
+	}
+}
+
+func TestLimiterLimitsBatches(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxBatchSize: 2, MaxBatchParallelism: 4})
+
+	if err := limiter.allowBatch(2); err != nil {
+		t.Errorf("expected 2 transactions to be allowed, got %s", err)
+	}
+	if body := newErrorBody(limiter.allowBatch(3)); body.Status != http.StatusBadRequest {
+		t.Errorf("expected 400 for 3 transactions, got %d", body.Status)
+	}
+	for requested, expected := range map[int]int{0: min(defaultBatchParallelism, 4), 2: 2, 10: 4} {
+		if parallelism := limiter.batchParallelism(requested); parallelism != expected {
+			t.Errorf("expected parallelism %d for %d requested, got %d", expected, requested, parallelism)
+		}
+	}
+}
+
+func TestLimiterLimitsArguments(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxArgs: 2})
+
+	if err := limiter.allow("alice", []string{"a", "b"}); err != nil {
+		t.Errorf("expected 2 arguments to be allowed, got %s", err)
+	}
+	if body := newErrorBody(limiter.allow("alice", []string{"a", "b", "c"})); body.Status != http.StatusBadRequest {
+		t.Errorf("expected 400 for 3 arguments, got %d", body.Status)
+	}
+}
+
+func TestNilLimiterAllowsEverything(t *testing.T) {
+	var limiter *Limiter
+	if err := limiter.allow("alice", make([]string, 1000)); err != nil {
+		t.Error(err)
+	}
+	release, err := limiter.acquireEndorsement(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
+
+func TestLimiterBoundsConcurrentEndorsements(t *testing.T) {
+	limiter := NewLimiter(Limits{MaxConcurrentEndorsements: 1, EndorsementWait: 10 * time.Millisecond})
+
+	release, err := limiter.acquireEndorsement(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rateLimitErr *rateLimitError
+	if _, err := limiter.acquireEndorsement(context.Background()); !errors.As(err, &rateLimitErr) {
+		t.Fatalf("expected rate limit error while the endorsement is in progress, got %v", err)
+	}
+
+	release()
+	release, err = limiter.acquireEndorsement(context.Background())
+	if err != nil {
+		t.Fatalf("expected endorsement after release, got %s", err)
+	}
+	release()
+}
+
+func TestWriteErrorSetsRetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+	writeError(w, httptest.NewRequest(http.MethodPost, "/invoke", nil), newRateLimitError(1500*time.Millisecond, "rate limit exceeded"))
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", w.Code)
+	}
+	if retryAfter := w.Header().Get("Retry-After"); retryAfter != "2" {
+		t.Errorf("expected Retry-After of 2 seconds, got %q", retryAfter)
+	}
+}
+
+func TestHandlerLimitsRequestBody(t *testing.T) {
+	setup := &OrgSetup{DefaultIdentity: "User1", Authenticator: IdentityHeader{}, Limiter: NewLimiter(Limits{MaxBodyBytes: 64})}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset","args":["` + strings.Repeat("x", 64) + `"]}`
+
+	for name, contentLength := range map[string]int64{"with length": int64(len(body)), "chunked": -1} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/invoke", strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+			r.ContentLength = contentLength
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("expected 413, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
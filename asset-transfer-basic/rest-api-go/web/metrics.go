@@ -0,0 +1,151 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Stages of a transaction, as recorded in metrics and spans.
+const (
+	stageEvaluate = "evaluate"
+	stageEndorse  = "endorse"
+	stageSubmit   = "submit"
+	stageCommit   = "commit"
+)
+
+// otherLabel is recorded in metrics in place of channels, chaincodes and functions that no rule names.
+const otherLabel = "other"
+
+// transactionLabels identify the chaincode function of a transaction in metrics, spans and logs.
+// Their values are chosen by callers, so metrics only record the values named by rules, see OrgSetup.metricLabels.
+type transactionLabels struct {
+	channelID   string
+	chaincodeID string
+	function    string
+}
+
+// bound returns the labels with otherLabel in place of each value that no matching rule names. Rules that match a
+// value with a wildcard do not name it.
+func (labels transactionLabels) bound(rules []Rule) transactionLabels {
+	bounded := transactionLabels{channelID: otherLabel, chaincodeID: otherLabel, function: otherLabel}
+	for _, rule := range rules {
+		if !rule.matches(labels.channelID, labels.chaincodeID, labels.function) {
+			continue
+		}
+		if rule.Channel == labels.channelID {
+			bounded.channelID = labels.channelID
+		}
+		if rule.Chaincode == labels.chaincodeID {
+			bounded.chaincodeID = labels.chaincodeID
+		}
+		if rule.Function == labels.function {
+			bounded.function = labels.function
+		}
+	}
+	return bounded
+}
+
+// Metrics counts the gateway calls of each stage of a transaction, and the validation codes of committed
+// transactions, in a Prometheus registry. It is safe to share between organizations, which are told apart
+// by the org label.
+type Metrics struct {
+	registry     *prometheus.Registry
+	requests     *prometheus.CounterVec
+	durations    *prometheus.HistogramVec
+	transactions *prometheus.CounterVec
+	labels       []Rule
+}
+
+// NewMetrics creates the metrics, in a registry that also holds the Go runtime and process metrics.
+// Channels, chaincodes and functions are recorded as label values if the given rules, or the rules of the Policy of
+// the organization, name them, and as "other" otherwise, so that callers cannot create any number of time series.
+func NewMetrics(labels ...Rule) *Metrics {
+	stageLabels := []string{"org", "stage", "channel", "chaincode", "function"}
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "rest_api",
+			Name:      "gateway_requests_total",
+			Help:      "Gateway calls by transaction stage and outcome, which is a gRPC status code name or OK.",
+		}, append(stageLabels, "code")),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "rest_api",
+			Name:      "gateway_request_duration_seconds",
+			Help:      "Latency of gateway calls by transaction stage.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, stageLabels),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "rest_api",
+			Name:      "transactions_total",
+			Help:      "Committed transactions by validation code, which is VALID for successful transactions.",
+		}, []string{"org", "channel", "chaincode", "function", "validation_code"}),
+		labels: labels,
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.durations,
+		m.transactions,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// metricRules returns the rules of the Metrics and of the organization's Policy, if it has one, without duplicates.
+// Handler computes them once, so that recording a metric does not walk the Policy.
+func (setup *OrgSetup) metricRules() []Rule {
+	if setup.Metrics == nil {
+		return nil
+	}
+	rules := slices.Clone(setup.Metrics.labels)
+	if policy, ok := setup.Authorizer.(Policy); ok {
+		for _, principalRules := range policy {
+			rules = append(rules, principalRules...)
+		}
+	}
+	seen := make(map[Rule]bool, len(rules))
+	return slices.DeleteFunc(rules, func(rule Rule) bool {
+		duplicate := seen[rule]
+		seen[rule] = true
+		return duplicate
+	})
+}
+
+// metricLabels bounds labels by the rules computed by Handler.
+func (setup *OrgSetup) metricLabels(labels transactionLabels) transactionLabels {
+	if setup.Metrics == nil {
+		return labels
+	}
+	return labels.bound(setup.boundedRules)
+}
+
+// observe records the outcome and latency of a stage. A nil Metrics records nothing.
+func (m *Metrics) observe(org, stage string, labels transactionLabels, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	code := "OK"
+	if err != nil {
+		code = newErrorBody(err).Code
+	}
+	m.requests.WithLabelValues(org, stage, labels.channelID, labels.chaincodeID, labels.function, code).Inc()
+	m.durations.WithLabelValues(org, stage, labels.channelID, labels.chaincodeID, labels.function).Observe(duration.Seconds())
+}
+
+// committed counts a transaction with the validation code of its commit status.
+func (m *Metrics) committed(org string, labels transactionLabels, validationCode string) {
+	if m == nil {
+		return
+	}
+	m.transactions.WithLabelValues(org, labels.channelID, labels.chaincodeID, labels.function, validationCode).Inc()
+}
@@ -0,0 +1,122 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrape(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMetrics()
+	labels := transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "TransferAsset"}
+	metrics.observe("Org1", stageEndorse, labels, 20*time.Millisecond, nil)
+	metrics.observe("Org1", stageEndorse, labels, time.Second, status.Error(codes.Aborted, "asset1 does not exist"))
+	metrics.committed("Org1", labels, "MVCC_READ_CONFLICT")
+
+	exposition := scrape(t, metrics.Handler())
+	for _, expected := range []string{
+		`rest_api_gateway_requests_total{chaincode="basic",channel="mychannel",code="OK",function="TransferAsset",org="Org1",stage="endorse"} 1`,
+		`rest_api_gateway_requests_total{chaincode="basic",channel="mychannel",code="Aborted",function="TransferAsset",org="Org1",stage="endorse"} 1`,
+		`rest_api_gateway_request_duration_seconds_count{chaincode="basic",channel="mychannel",function="TransferAsset",org="Org1",stage="endorse"} 2`,
+		`rest_api_transactions_total{chaincode="basic",channel="mychannel",function="TransferAsset",org="Org1",validation_code="MVCC_READ_CONFLICT"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(exposition, expected) {
+			t.Errorf("expected metrics to contain %s, got:\n%s", expected, exposition)
+		}
+	}
+}
+
+func TestObserveRecordsStage(t *testing.T) {
+	labels := transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "ReadAsset"}
+	setup := &OrgSetup{OrgName: "Org1", Metrics: NewMetrics(Rule{Channel: "mychannel", Chaincode: "basic", Function: "ReadAsset"})}
+	if _, err := setup.Handler(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := setup.observe(context.Background(), stageEvaluate, labels, func(ctx context.Context) error {
+		return status.Error(codes.Unavailable, "connection refused")
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected the stage error, got %v", err)
+	}
+	expected := `rest_api_gateway_requests_total{chaincode="basic",channel="mychannel",code="Unavailable",function="ReadAsset",org="Org1",stage="evaluate"} 1`
+	if exposition := scrape(t, setup.Metrics.Handler()); !strings.Contains(exposition, expected) {
+		t.Errorf("expected metrics to contain %s, got:\n%s", expected, exposition)
+	}
+}
+
+func TestMetricLabelsAreBoundedByRules(t *testing.T) {
+	setup := &OrgSetup{
+		Metrics:    NewMetrics(Rule{Channel: "mychannel", Chaincode: "basic", Function: "ReadAsset"}),
+		Authorizer: Policy{"alice": {{Channel: "mychannel", Chaincode: "basic", Function: "CreateAsset"}}, "*": {{Channel: "mychannel"}}},
+	}
+	if _, err := setup.Handler(); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		labels, expected transactionLabels
+	}{
+		{
+			labels:   transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "ReadAsset"},
+			expected: transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "ReadAsset"},
+		},
+		{
+			labels:   transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "CreateAsset"},
+			expected: transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "CreateAsset"},
+		},
+		{
+			labels:   transactionLabels{channelID: "mychannel", chaincodeID: "random1", function: "random2"},
+			expected: transactionLabels{channelID: "mychannel", chaincodeID: otherLabel, function: otherLabel},
+		},
+		{
+			labels:   transactionLabels{channelID: "random1", chaincodeID: "basic", function: "ReadAsset"},
+			expected: transactionLabels{channelID: otherLabel, chaincodeID: otherLabel, function: otherLabel},
+		},
+	} {
+		if actual := setup.metricLabels(test.labels); actual != test.expected {
+			t.Errorf("expected %+v to be recorded as %+v, got %+v", test.labels, test.expected, actual)
+		}
+	}
+
+	setup.Authorizer = nil
+	if _, err := setup.Handler(); err != nil {
+		t.Fatal(err)
+	}
+	labels := transactionLabels{channelID: "mychannel", chaincodeID: "basic", function: "CreateAsset"}
+	if actual := setup.metricLabels(labels); actual.function != otherLabel {
+		t.Errorf("expected function not named without a policy to be recorded as %s, got %+v", otherLabel, actual)
+	}
+}
+
+func TestHandlerServesMetricsWithoutCredentials(t *testing.T) {
+	setup := &OrgSetup{
+		Authenticator: NewAPIKeys(map[string]Principal{"secret": {Name: "bob"}}),
+		Metrics:       NewMetrics(),
+	}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exposition := scrape(t, handler); !strings.Contains(exposition, "go_goroutines") {
+		t.Errorf("expected metrics, got:\n%s", exposition)
+	}
+}
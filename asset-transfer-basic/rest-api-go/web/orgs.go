@@ -0,0 +1,132 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// DefaultOrgHeader is the request header that selects the organization by name or MSP ID.
+const DefaultOrgHeader = "X-Fabric-Org"
+
+// Orgs serves the REST API of several organizations from one server. Each request is routed to the gateway of the
+// organization named by an /orgs/{org} path prefix or the X-Fabric-Org header, or else of the default organization.
+// Organizations are named by OrgName or MSPID, ignoring case.
+type Orgs struct {
+	setups    []*OrgSetup
+	lifecycle *lifecycle
+}
+
+// NewOrgs routes requests to the given organizations. The first is the default organization.
+func NewOrgs(setups ...*OrgSetup) (*Orgs, error) {
+	if len(setups) == 0 {
+		return nil, errors.New("no organizations configured")
+	}
+	orgs := &Orgs{lifecycle: newLifecycle()}
+	for _, setup := range setups {
+		if setup.OrgName == "" {
+			return nil, fmt.Errorf("organization %s has no name", setup.MSPID)
+		}
+		if orgs.lookup(setup.OrgName) != nil || (setup.MSPID != "" && orgs.lookup(setup.MSPID) != nil) {
+			return nil, fmt.Errorf("duplicate organization %s", setup.OrgName)
+		}
+		orgs.setups = append(orgs.setups, setup)
+	}
+	return orgs, nil
+}
+
+// lookup returns the organization with the given name or MSP ID, or nil if there is none.
+func (orgs *Orgs) lookup(name string) *OrgSetup {
+	for _, setup := range orgs.setups {
+		if strings.EqualFold(setup.OrgName, name) || strings.EqualFold(setup.MSPID, name) {
+			return setup
+		}
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler that traces requests and routes them to the handler of each organization.
+// The /healthz and /readyz probes at the root cover every organization; under /orgs/{org} they cover one.
+func (orgs *Orgs) Handler() (http.Handler, error) {
+	handlers := make(map[*OrgSetup]http.Handler, len(orgs.setups))
+	for _, setup := range orgs.setups {
+		setup.lifecycle = orgs.lifecycle
+		handler, err := setup.Handler()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create handler for %s: %w", setup.OrgName, err)
+		}
+		handlers[setup] = handler
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Health{Status: "ok"})
+	})
+	mux.HandleFunc("GET /readyz", orgs.Readyz)
+	mux.HandleFunc("/orgs/{org}/", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("org")
+		setup := orgs.lookup(name)
+		if setup == nil {
+			writeError(w, r, unknownOrg(name))
+			return
+		}
+		if header := r.Header.Get(DefaultOrgHeader); header != "" && orgs.lookup(header) != setup {
+			writeError(w, r, badRequest("%s header %s does not match organization %s in the path", DefaultOrgHeader, header, name))
+			return
+		}
+		prefix := "/orgs/" + name
+		r = r.WithContext(context.WithValue(r.Context(), basePathKey{}, prefix))
+		http.StripPrefix(prefix, handlers[setup]).ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		setup := orgs.setups[0]
+		if name := r.Header.Get(DefaultOrgHeader); name != "" {
+			if setup = orgs.lookup(name); setup == nil {
+				writeError(w, r, unknownOrg(name))
+				return
+			}
+		}
+		handlers[setup].ServeHTTP(w, r)
+	})
+	return traceRequests(mux), nil
+}
+
+// Readyz reports whether every organization can process transactions, by probing their gateway peers.
+func (orgs *Orgs) Readyz(w http.ResponseWriter, r *http.Request) {
+	if orgs.lifecycle.draining.Load() {
+		writeError(w, r, errShuttingDown)
+		return
+	}
+	for _, setup := range orgs.setups {
+		if err := setup.probeGateway(r.Context()); err != nil {
+			slog.WarnContext(r.Context(), "Readiness check failed", "org", setup.OrgName, "error", err)
+			writeError(w, r, errGatewayUnavailable)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, Health{Status: "ok"})
+}
+
+// Close closes the gateway connections of every organization.
+func (orgs *Orgs) Close() error {
+	var errs []error
+	for _, setup := range orgs.setups {
+		errs = append(errs, setup.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func unknownOrg(name string) error {
+	return &requestError{status: http.StatusNotFound, code: "NOT_FOUND", err: fmt.Errorf("unknown organization %s", name)}
+}
+
+type basePathKey struct{}
+
+// resourcePath returns the path of a resource of the API, under the /orgs/{org} prefix the request was routed by.
+func resourcePath(ctx context.Context, path string) string {
+	prefix, _ := ctx.Value(basePathKey{}).(string)
+	return prefix + path
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// newOrgSetup returns an organization that has submitted the transaction with the given ID.
+func newOrgSetup(name, mspID, txID string) *OrgSetup {
+	setup := &OrgSetup{OrgName: name, MSPID: mspID, DefaultIdentity: "User1", Authenticator: IdentityHeader{}, transactions: newTransactionTracker()}
+	setup.transactions.track("", txID, nil, func() (*client.Status, error) {
+		return nil, errors.New("no commit status")
+	})
+	return setup
+}
+
+func TestNewOrgsRejectsDuplicates(t *testing.T) {
+	if _, err := NewOrgs(newOrgSetup("Org1", "Org1MSP", "tx1"), newOrgSetup("org1", "OtherMSP", "tx2")); err == nil {
+		t.Error("expected an error for duplicate names")
+	}
+	if _, err := NewOrgs(newOrgSetup("Org1", "Org1MSP", "tx1"), newOrgSetup("Org2", "Org1MSP", "tx2")); err == nil {
+		t.Error("expected an error for duplicate MSP IDs")
+	}
+	if _, err := NewOrgs(); err == nil {
+		t.Error("expected an error without organizations")
+	}
+}
+
+func TestOrgsRouting(t *testing.T) {
+	orgs, err := NewOrgs(newOrgSetup("Org1", "Org1MSP", "tx1"), newOrgSetup("Org2", "Org2MSP", "tx2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := orgs.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name     string
+		path     string
+		org      string
+		expected int
+	}{
+		{name: "default organization", path: "/transactions/tx1", expected: http.StatusOK},
+		{name: "other organization by default", path: "/transactions/tx2", expected: http.StatusNotFound},
+		{name: "path", path: "/orgs/Org2/transactions/tx2", expected: http.StatusOK},
+		{name: "path by MSP ID", path: "/orgs/org2msp/transactions/tx2", expected: http.StatusOK},
+		{name: "header", path: "/transactions/tx2", org: "Org2", expected: http.StatusOK},
+		{name: "matching path and header", path: "/orgs/Org2/transactions/tx2", org: "Org2MSP", expected: http.StatusOK},
+		{name: "conflicting path and header", path: "/orgs/Org2/transactions/tx2", org: "Org1", expected: http.StatusBadRequest},
+		{name: "unknown path", path: "/orgs/Org3/transactions/tx2", expected: http.StatusNotFound},
+		{name: "unknown header", path: "/transactions/tx2", org: "Org3", expected: http.StatusNotFound},
+		{name: "health", path: "/healthz", expected: http.StatusOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.org != "" {
+				r.Header.Set(DefaultOrgHeader, test.org)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != test.expected {
+				t.Errorf("expected %d, got %d: %s", test.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestResourcePath(t *testing.T) {
+	if path := resourcePath(context.Background(), "/transactions/tx1"); path != "/transactions/tx1" {
+		t.Errorf("unexpected path %s", path)
+	}
+	ctx := context.WithValue(context.Background(), basePathKey{}, "/orgs/Org2")
+	if path := resourcePath(ctx, "/transactions/tx1"); path != "/orgs/Org2/transactions/tx1" {
+		t.Errorf("unexpected path %s", path)
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// AnyPrincipal is the Policy key whose rules apply to every principal.
+const AnyPrincipal = "*"
+
+// Authorizer decides whether a principal may run a chaincode function.
+type Authorizer interface {
+	Authorize(principal *Principal, channelID, chaincodeID, function string) error
+}
+
+// Rule allows the transactions that match its channel, chaincode and function.
+// An empty field, or "*", matches anything.
+type Rule struct {
+	Channel   string `json:"channel,omitempty"`
+	Chaincode string `json:"chaincode,omitempty"`
+	Function  string `json:"function,omitempty"`
+}
+
+func (rule Rule) matches(channelID, chaincodeID, function string) bool {
+	return matchesPattern(rule.Channel, channelID) && matchesPattern(rule.Chaincode, chaincodeID) && matchesPattern(rule.Function, function)
+}
+
+func matchesPattern(pattern, value string) bool {
+	return pattern == "" || pattern == "*" || pattern == value
+}
+
+// Policy allowlists the transactions each principal may run, keyed by principal name.
+// Principals without a matching rule, under their own name or AnyPrincipal, are denied.
+type Policy map[string][]Rule
+
+// LoadPolicy reads a Policy from a JSON file, for example:
+//
+//	{"alice": [{"channel": "mychannel", "chaincode": "basic"}], "*": [{"function": "ReadAsset"}]}
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	policy := Policy{}
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return policy, nil
+}
+
+// AllowAll is an Authorizer that allows every principal to run any chaincode function.
+type AllowAll struct{}
+
+// Authorize implements Authorizer.
+func (AllowAll) Authorize(*Principal, string, string, string) error {
+	return nil
+}
+
+// Authorize implements Authorizer.
+func (p Policy) Authorize(principal *Principal, channelID, chaincodeID, function string) error {
+	for _, rules := range [][]Rule{p[principal.Name], p[AnyPrincipal]} {
+		for _, rule := range rules {
+			if rule.matches(channelID, chaincodeID, function) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%s is not allowed to call %s on chaincode %s in channel %s", principal.Name, function, chaincodeID, channelID)
+}
+
+// authorize checks the configured Authorizer for the request's principal. Without an Authorizer every call is denied.
+func (setup *OrgSetup) authorize(ctx context.Context, channelID, chaincodeID, function string) error {
+	if setup.Authorizer == nil {
+		return &requestError{status: http.StatusForbidden, code: "PERMISSION_DENIED", err: errors.New("no authorization policy configured")}
+	}
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return &requestError{status: http.StatusUnauthorized, code: "UNAUTHENTICATED", err: fmt.Errorf("no principal")}
+	}
+	if err := setup.Authorizer.Authorize(principal, channelID, chaincodeID, function); err != nil {
+		return &requestError{status: http.StatusForbidden, code: "PERMISSION_DENIED", err: err}
+	}
+	return nil
+}
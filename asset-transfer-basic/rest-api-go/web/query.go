@@ -1,25 +1,55 @@
 package web
 
 import (
-	"fmt"
-	"net/http"
+	"context"
+	"log/slog"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
-// Query handles chaincode query requests.
-func (setup OrgSetup) Query(w http.ResponseWriter, r *http.Request) {
-	fmt.Println("Received Query request")
-	queryParams := r.URL.Query()
-	chainCodeName := queryParams.Get("chaincodeid")
-	channelID := queryParams.Get("channelid")
-	function := queryParams.Get("function")
-	args := r.URL.Query()["args"]
-	fmt.Printf("channel: %s, chaincode: %s, function: %s, args: %s\n", channelID, chainCodeName, function, args)
-	network := setup.Gateway.GetNetwork(channelID)
-	contract := network.GetContract(chainCodeName)
-	evaluateResponse, err := contract.EvaluateTransaction(function, args...)
+// Query evaluates the transaction function named in the query parameters.
+func (setup *OrgSetup) Query(ctx context.Context, request QueryRequestObject) (QueryResponseObject, error) {
+	params := request.Params
+	result, err := setup.evaluate(ctx, params.Channelid, params.Chaincodeid, params.Function, params.Args)
+	if err != nil {
+		return nil, err
+	}
+	return Query200JSONResponse{TransactionSuccessJSONResponse{Result: chaincodeResult(result)}}, nil
+}
+
+// Evaluate evaluates the transaction function named in the JSON request body.
+func (setup *OrgSetup) Evaluate(ctx context.Context, request EvaluateRequestObject) (EvaluateResponseObject, error) {
+	body := request.Body
+	result, err := setup.evaluate(ctx, body.ChannelId, body.ChaincodeId, body.Function, body.Args, proposalOptions(*body)...)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate200JSONResponse{TransactionSuccessJSONResponse{Result: chaincodeResult(result)}}, nil
+}
+
+// evaluate evaluates a transaction function as the identity of the request's principal.
+func (setup *OrgSetup) evaluate(ctx context.Context, channelID, chaincodeID, function string, args []string, options ...client.ProposalOption) ([]byte, error) {
+	slog.InfoContext(ctx, "Received Query request", "channel", channelID, "chaincode", chaincodeID, "function", function, "args", args)
+	if err := setup.authorize(ctx, channelID, chaincodeID, function); err != nil {
+		return nil, err
+	}
+	gateway, err := setup.requestGateway(ctx)
 	if err != nil {
-		fmt.Fprintf(w, "Error: %s", err)
-		return
+		return nil, err
+	}
+	principal, _ := PrincipalFromContext(ctx)
+	if err := setup.Limiter.allow(principal.Name, args); err != nil {
+		return nil, err
 	}
-	fmt.Fprintf(w, "Response: %s", evaluateResponse)
+	network := gateway.GetNetwork(channelID)
+	contract := network.GetContract(chaincodeID)
+	options = append([]client.ProposalOption{client.WithArguments(args...)}, options...)
+
+	var result []byte
+	labels := transactionLabels{channelID: channelID, chaincodeID: chaincodeID, function: function}
+	err = setup.observe(ctx, stageEvaluate, labels, func(ctx context.Context) (err error) {
+		result, err = contract.EvaluateWithContext(ctx, function, options...)
+		return err
+	})
+	return result, err
 }
@@ -0,0 +1,182 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatuses maps gRPC status codes to HTTP status codes, following grpc-gateway.
+var httpStatuses = map[codes.Code]int{
+	codes.OK:                 http.StatusOK,
+	codes.Canceled:           499,
+	codes.Unknown:            http.StatusInternalServerError,
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.NotFound:           http.StatusNotFound,
+	codes.AlreadyExists:      http.StatusConflict,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.ResourceExhausted:  http.StatusTooManyRequests,
+	codes.FailedPrecondition: http.StatusBadRequest,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusBadRequest,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.Internal:           http.StatusInternalServerError,
+	codes.Unavailable:        http.StatusServiceUnavailable,
+	codes.DataLoss:           http.StatusInternalServerError,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+}
+
+// requestError is a failure detected by the REST server itself, such as a malformed request body.
+type requestError struct {
+	status int
+	code   string
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// badRequest returns an error that is reported with HTTP 400.
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, code: "BAD_REQUEST", err: fmt.Errorf(format, args...)}
+}
+
+// commitError reports a transaction that committed with a validation code other than VALID.
+type commitError struct {
+	status *client.Status
+}
+
+func (e *commitError) Error() string {
+	return fmt.Sprintf("transaction %s failed to commit with status code %d (%s)", e.status.TransactionID, int32(e.status.Code), e.status.Code)
+}
+
+// newErrorBody classifies err into an HTTP status and a structured error body.
+func newErrorBody(err error) ErrorBody {
+	var requestErr *requestError
+	if errors.As(err, &requestErr) {
+		return ErrorBody{Status: requestErr.status, Code: requestErr.code, Message: err.Error()}
+	}
+
+	var commitErr *commitError
+	if errors.As(err, &commitErr) {
+		return ErrorBody{
+			Status:         http.StatusConflict,
+			Code:           "COMMIT_FAILED",
+			Message:        err.Error(),
+			TxId:           commitErr.status.TransactionID,
+			ValidationCode: commitErr.status.Code.String(),
+		}
+	}
+
+	body := ErrorBody{Status: http.StatusInternalServerError, Code: codes.Unknown.String(), Message: err.Error()}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		body.Status, body.Code = http.StatusGatewayTimeout, codes.DeadlineExceeded.String()
+	case errors.Is(err, context.Canceled):
+		body.Status, body.Code = 499, codes.Canceled.String()
+	}
+
+	if statusErr, ok := status.FromError(err); ok {
+		body.Code = statusErr.Code().String()
+		body.Status = httpStatuses[statusErr.Code()]
+		for _, detail := range statusErr.Details() {
+			if errorDetail, ok := detail.(*gateway.ErrorDetail); ok {
+				body.Details = append(body.Details, ErrorDetail{
+					Address: errorDetail.GetAddress(),
+					MspId:   errorDetail.GetMspId(),
+					Message: errorDetail.GetMessage(),
+				})
+			}
+		}
+	}
+	body.TxId = transactionID(err)
+
+	// Chaincode errors reach the client as Aborted or Unknown; refine them using the chaincode's message
+	if body.Code == codes.Aborted.String() || body.Code == codes.Unknown.String() {
+		for _, message := range body.messages() {
+			switch {
+			case strings.Contains(message, "does not exist"):
+				body.Status = http.StatusNotFound
+				return body
+			case strings.Contains(message, "already exists"):
+				body.Status = http.StatusConflict
+				return body
+			case strings.Contains(message, "chaincode response"):
+				body.Status = http.StatusBadRequest
+			}
+		}
+	}
+	return body
+}
+
+// messages returns the peer error messages, or the top-level message if there are none.
+func (body ErrorBody) messages() []string {
+	if len(body.Details) == 0 {
+		return []string{body.Message}
+	}
+	messages := make([]string, len(body.Details))
+	for i, detail := range body.Details {
+		messages[i] = detail.Message
+	}
+	return messages
+}
+
+// transactionID returns the transaction ID carried by a fabric-gateway client error, if any.
+func transactionID(err error) string {
+	var endorseErr *client.EndorseError
+	var submitErr *client.SubmitError
+	var commitStatusErr *client.CommitStatusError
+	switch {
+	case errors.As(err, &endorseErr):
+		return endorseErr.TransactionID
+	case errors.As(err, &submitErr):
+		return submitErr.TransactionID
+	case errors.As(err, &commitStatusErr):
+		return commitStatusErr.TransactionID
+	}
+	return ""
+}
+
+// chaincodeResult returns the chaincode response as raw JSON when it is valid JSON, and as a string otherwise.
+func chaincodeResult(result []byte) any {
+	if len(result) > 0 && json.Valid(result) {
+		return json.RawMessage(result)
+	}
+	return string(result)
+}
+
+// writeJSON writes body as JSON with the given HTTP status.
+func writeJSON(w http.ResponseWriter, httpStatus int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Error writing response", "error", err)
+	}
+}
+
+// writeError writes err as a structured JSON error with the HTTP status derived from it, and logs it in the context
+// of the request.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	body := newErrorBody(err)
+	slog.WarnContext(r.Context(), "Request failed", "status", body.Status, "code", body.Code, "error", body.Message)
+	var rateLimitErr *rateLimitError
+	if errors.As(err, &rateLimitErr) {
+		w.Header().Set("Retry-After", rateLimitErr.retryAfterSeconds())
+	}
+	writeJSON(w, body.Status, ErrorResponse{Error: body})
+}
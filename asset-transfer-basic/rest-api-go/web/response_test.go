@@ -0,0 +1,132 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func gatewayError(t *testing.T, code codes.Code, message string) error {
+	t.Helper()
+	statusErr, err := status.New(code, "failed to endorse transaction, see attached details for more info").WithDetails(
+		&gateway.ErrorDetail{Address: "peer0.org1.example.com:7051", MspId: "Org1MSP", Message: message},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statusErr.Err()
+}
+
+func Test_newErrorBody(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		err    error
+		status int
+		code   string
+	}{
+		"bad request":       {badRequest("function is required"), http.StatusBadRequest, "BAD_REQUEST"},
+		"asset not found":   {gatewayError(t, codes.Aborted, "chaincode response 500, the asset asset1 does not exist"), http.StatusNotFound, "Aborted"},
+		"asset exists":      {gatewayError(t, codes.Aborted, "chaincode response 500, the asset asset1 already exists"), http.StatusConflict, "Aborted"},
+		"chaincode error":   {gatewayError(t, codes.Aborted, "chaincode response 500, invalid size"), http.StatusBadRequest, "Aborted"},
+		"unavailable":       {status.Error(codes.Unavailable, "connection refused"), http.StatusServiceUnavailable, "Unavailable"},
+		"deadline exceeded": {status.Error(codes.DeadlineExceeded, "endorse timeout"), http.StatusGatewayTimeout, "DeadlineExceeded"},
+		"context deadline":  {fmt.Errorf("waiting: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, "DeadlineExceeded"},
+		"commit failed": {
+			&commitError{status: &client.Status{TransactionID: "tx1", Code: peer.TxValidationCode_MVCC_READ_CONFLICT}},
+			http.StatusConflict, "COMMIT_FAILED",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := newErrorBody(testCase.err)
+			if body.Status != testCase.status || body.Code != testCase.code {
+				t.Errorf("expected %d %s, got %d %s", testCase.status, testCase.code, body.Status, body.Code)
+			}
+		})
+	}
+
+	body := newErrorBody(gatewayError(t, codes.Aborted, "chaincode response 500, the asset asset1 does not exist"))
+	if len(body.Details) != 1 || body.Details[0].MspId != "Org1MSP" {
+		t.Errorf("expected peer error details, got %+v", body.Details)
+	}
+}
+
+func TestHandlerValidatesRequests(t *testing.T) {
+	setup := &OrgSetup{DefaultIdentity: "User1", Authenticator: IdentityHeader{}}
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testCase := range map[string]struct {
+		method, target, contentType, body string
+	}{
+		"missing query parameter": {http.MethodGet, "/query?channelid=mychannel&chaincodeid=basic", "", ""},
+		"unknown JSON field":      {http.MethodPost, "/invoke", "application/json", `{"channelId":"mychannel","chaincodeId":"basic","function":"CreateAsset","argz":[]}`},
+		"missing form parameter":  {http.MethodPost, "/invoke", "application/x-www-form-urlencoded", url.Values{"channelid": {"mychannel"}, "function": {"CreateAsset"}}.Encode()},
+		"unsupported body":        {http.MethodPost, "/invoke", "text/plain", "CreateAsset"},
+		"transient not base64":    {http.MethodPost, "/invoke", "application/json", `{"channelId":"mychannel","chaincodeId":"private","function":"CreateAsset","transient":{"asset_properties":"{\"ID\":\"asset1\"}"}}`},
+		"empty endorsing org":     {http.MethodPost, "/invoke", "application/json", `{"channelId":"mychannel","chaincodeId":"private","function":"CreateAsset","endorsingOrgs":[""]}`},
+		"negative asset size":     {http.MethodPost, "/assets", "application/json", `{"ID":"asset1","Color":"blue","Size":-1,"Owner":"Tom","AppraisedValue":100}`},
+		"missing new owner":       {http.MethodPut, "/assets/asset1/owner", "application/json", `{}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(testCase.method, testCase.target, strings.NewReader(testCase.body))
+			if testCase.contentType != "" {
+				r.Header.Set("Content-Type", testCase.contentType)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			response := ErrorResponse{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("expected JSON error, got %q", w.Body.String())
+			}
+			if w.Code != http.StatusBadRequest || response.Error.Status != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerAcceptsFormWithoutArgs(t *testing.T) {
+	// An empty policy denies every call, so a valid request fails with 403 after validation
+	handler, err := (&OrgSetup{DefaultIdentity: "User1", Authenticator: IdentityHeader{}, Authorizer: Policy{}}).Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	form := url.Values{"channelid": {"mychannel"}, "chaincodeid": {"basic"}, "function": {"InitLedger"}}
+	r := httptest.NewRequest(http.MethodPost, "/invoke", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected form without args to pass validation, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlerServesSpec(t *testing.T) {
+	handler, err := (&OrgSetup{}).Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	spec := map[string]any{}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal("expected JSON spec:", err)
+	}
+	if paths, _ := spec["paths"].(map[string]any); paths["/assets/{id}/owner"] == nil || paths["/events/chaincode"] == nil {
+		t.Errorf("expected asset and event paths in spec, got %v", spec["paths"])
+	}
+}
@@ -0,0 +1,1413 @@
+//go:build go1.22
+
+// Package web provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/oapi-codegen/runtime"
+	strictnethttp "github.com/oapi-codegen/runtime/strictmiddleware/nethttp"
+)
+
+const (
+	AccessTokenScopes = "accessToken.Scopes"
+	ApiKeyScopes      = "apiKey.Scopes"
+	BearerAuthScopes  = "bearerAuth.Scopes"
+)
+
+// Defines values for BatchResultStatus.
+const (
+	BatchCommitted BatchResultStatus = "committed"
+	BatchError     BatchResultStatus = "error"
+	BatchFailed    BatchResultStatus = "failed"
+	BatchSkipped   BatchResultStatus = "skipped"
+)
+
+// Defines values for TransactionStatusStatus.
+const (
+	TransactionCommitted TransactionStatusStatus = "committed"
+	TransactionFailed    TransactionStatusStatus = "failed"
+	TransactionPending   TransactionStatusStatus = "pending"
+	TransactionUnknown   TransactionStatusStatus = "unknown"
+)
+
+// Asset defines model for Asset.
+type Asset struct {
+	AppraisedValue int    `json:"AppraisedValue"`
+	Color          string `json:"Color"`
+	ID             string `json:"ID"`
+	Owner          string `json:"Owner"`
+	Size           int    `json:"Size"`
+}
+
+// BatchRequest defines model for BatchRequest.
+type BatchRequest struct {
+	// Parallelism transactions to endorse and submit at once, 8 by default and at most the server's limit
+	Parallelism int `json:"parallelism,omitempty"`
+
+	// StopOnFailure skip the transactions that have not started once a transaction fails
+	StopOnFailure bool                 `json:"stopOnFailure,omitempty"`
+	Transactions  []TransactionRequest `json:"transactions"`
+}
+
+// BatchResponse defines model for BatchResponse.
+type BatchResponse struct {
+	// Results outcome of each transaction, in the order of the request
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult defines model for BatchResult.
+type BatchResult struct {
+	BlockNumber uint64     `json:"blockNumber,omitempty"`
+	Error       *ErrorBody `json:"error,omitempty"`
+
+	// Result chaincode response, as JSON if it is valid JSON and as a string otherwise
+	Result interface{} `json:"result,omitempty"`
+
+	// Status committed if the transaction committed as VALID, failed if it committed with another validation code,
+	// error if it could not be endorsed or submitted, and skipped if it was not sent because of stopOnFailure
+	// or the batch timeout.
+	Status BatchResultStatus `json:"status"`
+
+	// TxId transaction id; absent if the transaction was skipped or rejected before it was created
+	TxId           string `json:"txId,omitempty"`
+	ValidationCode string `json:"validationCode,omitempty"`
+}
+
+// BatchResultStatus committed if the transaction committed as VALID, failed if it committed with another validation code,
+// error if it could not be endorsed or submitted, and skipped if it was not sent because of stopOnFailure
+// or the batch timeout.
+type BatchResultStatus string
+
+// BlockEvent defines model for BlockEvent.
+type BlockEvent struct {
+	BlockNumber  uint64             `json:"blockNumber"`
+	Transactions []BlockTransaction `json:"transactions"`
+}
+
+// BlockTransaction defines model for BlockTransaction.
+type BlockTransaction struct {
+	TxId string `json:"txId"`
+
+	// Type header type, ENDORSER_TRANSACTION for chaincode transactions
+	Type           string `json:"type"`
+	ValidationCode string `json:"validationCode"`
+}
+
+// ChaincodeEvent defines model for ChaincodeEvent.
+type ChaincodeEvent struct {
+	BlockNumber   uint64 `json:"blockNumber"`
+	ChaincodeName string `json:"chaincodeName"`
+	EventName     string `json:"eventName"`
+
+	// Payload event payload, as JSON if it is valid JSON and as a string otherwise
+	Payload interface{} `json:"payload,omitempty"`
+	TxId    string      `json:"txId"`
+}
+
+// ErrorBody defines model for ErrorBody.
+type ErrorBody struct {
+	// Code gRPC status code name for gateway failures, or a short reason for failures of the request itself
+	Code string `json:"code"`
+
+	// Details errors reported by each peer or orderer
+	Details []ErrorDetail `json:"details,omitempty"`
+	Message string        `json:"message"`
+
+	// Status HTTP status code
+	Status int    `json:"status"`
+	TxId   string `json:"txId,omitempty"`
+
+	// ValidationCode validation code of a transaction that failed to commit
+	ValidationCode string `json:"validationCode,omitempty"`
+}
+
+// ErrorDetail defines model for ErrorDetail.
+type ErrorDetail struct {
+	Address string `json:"address"`
+	Message string `json:"message"`
+	MspId   string `json:"mspId"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Health defines model for Health.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// OwnerRequest defines model for OwnerRequest.
+type OwnerRequest struct {
+	Owner string `json:"owner"`
+}
+
+// TransactionForm Form parameters accepted by earlier versions of the /invoke endpoint. Transient data can only be sent
+// in a JSON body, so forms with other parameters are rejected.
+type TransactionForm struct {
+	Args        []string `json:"args"`
+	Chaincodeid string   `json:"chaincodeid"`
+	Channelid   string   `json:"channelid"`
+
+	// EndorsingOrgs MSP IDs of the organizations whose peers must endorse the transaction,
+	// instead of those chosen by the gateway from the endorsement policy
+	EndorsingOrgs []string `json:"endorsingOrgs"`
+	Function      string   `json:"function"`
+}
+
+// TransactionRequest defines model for TransactionRequest.
+type TransactionRequest struct {
+	// Args chaincode function arguments, in order
+	Args        []string `json:"args,omitempty"`
+	ChaincodeId string   `json:"chaincodeId"`
+	ChannelId   string   `json:"channelId"`
+
+	// EndorsingOrgs MSP IDs of the organizations whose peers must endorse or evaluate the transaction,
+	// instead of those chosen by the gateway from the endorsement policy
+	EndorsingOrgs []string `json:"endorsingOrgs,omitempty"`
+
+	// Function chaincode function name
+	Function string `json:"function"`
+
+	// Transient transient data, such as private data, by key with base64 encoded values;
+	// passed to the chaincode but not recorded on the ledger
+	Transient map[string][]byte `json:"transient,omitempty"`
+}
+
+// TransactionResponse defines model for TransactionResponse.
+type TransactionResponse struct {
+	// BlockNumber block in which the transaction committed
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+
+	// Result chaincode response, as JSON if it is valid JSON and as a string otherwise
+	Result interface{} `json:"result"`
+
+	// TxId transaction id; absent for evaluated transactions
+	TxId string `json:"txId,omitempty"`
+
+	// ValidationCode validation code of the committed transaction, VALID on success
+	ValidationCode string `json:"validationCode,omitempty"`
+}
+
+// TransactionStatus defines model for TransactionStatus.
+type TransactionStatus struct {
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+
+	// Error why the commit status could not be obtained
+	Error string `json:"error,omitempty"`
+
+	// Result chaincode response, as JSON if it is valid JSON and as a string otherwise
+	Result interface{} `json:"result,omitempty"`
+
+	// Status pending until the transaction commits. committed if it committed as VALID, failed if it committed
+	// with another validation code, and unknown if its commit status could not be obtained.
+	Status         TransactionStatusStatus `json:"status"`
+	TxId           string                  `json:"txId"`
+	ValidationCode string                  `json:"validationCode,omitempty"`
+}
+
+// TransactionStatusStatus pending until the transaction commits. committed if it committed as VALID, failed if it committed
+// with another validation code, and unknown if its commit status could not be obtained.
+type TransactionStatusStatus string
+
+// LastEventID defines model for Last-Event-ID.
+type LastEventID = string
+
+// Args defines model for args.
+type Args = []string
+
+// Chaincodeid defines model for chaincodeid.
+type Chaincodeid = string
+
+// Channel defines model for channel.
+type Channel = string
+
+// Channelid defines model for channelid.
+type Channelid = string
+
+// Function defines model for function.
+type Function = string
+
+// Id defines model for id.
+type Id = string
+
+// LastEventId defines model for lastEventId.
+type LastEventId = string
+
+// StartBlock defines model for startBlock.
+type StartBlock = uint64
+
+// TooManyRequests defines model for TooManyRequests.
+type TooManyRequests = ErrorResponse
+
+// TransactionAccepted defines model for TransactionAccepted.
+type TransactionAccepted = TransactionStatus
+
+// TransactionSuccess defines model for TransactionSuccess.
+type TransactionSuccess = TransactionResponse
+
+// InvokeParams defines parameters for Invoke.
+type InvokeParams struct {
+	// Async respond once the transaction is submitted, without waiting for it to commit
+	Async bool `form:"async,omitempty" json:"async,omitempty"`
+}
+
+// QueryParams defines parameters for Query.
+type QueryParams struct {
+	Channelid   Channelid   `form:"channelid" json:"channelid"`
+	Chaincodeid Chaincodeid `form:"chaincodeid" json:"chaincodeid"`
+
+	// Function chaincode function name
+	Function Function `form:"function" json:"function"`
+
+	// Args chaincode function arguments, in order
+	Args Args `form:"args,omitempty" json:"args,omitempty"`
+}
+
+// GetTransactionParams defines parameters for GetTransaction.
+type GetTransactionParams struct {
+	// Channel channel name, by default the channel of the asset chaincode configured on the server
+	Channel Channel `form:"channel,omitempty" json:"channel,omitempty"`
+}
+
+// CreateAssetJSONRequestBody defines body for CreateAsset for application/json ContentType.
+type CreateAssetJSONRequestBody = Asset
+
+// TransferAssetJSONRequestBody defines body for TransferAsset for application/json ContentType.
+type TransferAssetJSONRequestBody = OwnerRequest
+
+// InvokeJSONRequestBody defines body for Invoke for application/json ContentType.
+type InvokeJSONRequestBody = TransactionRequest
+
+// InvokeFormdataRequestBody defines body for Invoke for application/x-www-form-urlencoded ContentType.
+type InvokeFormdataRequestBody = TransactionForm
+
+// InvokeBatchJSONRequestBody defines body for InvokeBatch for application/json ContentType.
+type InvokeBatchJSONRequestBody = BatchRequest
+
+// EvaluateJSONRequestBody defines body for Evaluate for application/json ContentType.
+type EvaluateJSONRequestBody = TransactionRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Create an asset
+	// (POST /assets)
+	CreateAsset(w http.ResponseWriter, r *http.Request)
+	// Read an asset
+	// (GET /assets/{id})
+	ReadAsset(w http.ResponseWriter, r *http.Request, id Id)
+	// Transfer an asset to a new owner
+	// (PUT /assets/{id}/owner)
+	TransferAsset(w http.ResponseWriter, r *http.Request, id Id)
+	// Submit a transaction and wait for it to commit
+	// (POST /invoke)
+	Invoke(w http.ResponseWriter, r *http.Request, params InvokeParams)
+	// Submit several transactions and wait for them to commit
+	// (POST /invoke/batch)
+	InvokeBatch(w http.ResponseWriter, r *http.Request)
+	// Evaluate a transaction function
+	// (GET /query)
+	Query(w http.ResponseWriter, r *http.Request, params QueryParams)
+	// Evaluate a transaction function
+	// (POST /query)
+	Evaluate(w http.ResponseWriter, r *http.Request)
+	// Get the status of a transaction submitted with async=true
+	// (GET /transactions/{txid})
+	GetTransaction(w http.ResponseWriter, r *http.Request, txid string, params GetTransactionParams)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// CreateAsset operation middleware
+func (siw *ServerInterfaceWrapper) CreateAsset(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateAsset(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ReadAsset operation middleware
+func (siw *ServerInterfaceWrapper) ReadAsset(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id Id
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReadAsset(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// TransferAsset operation middleware
+func (siw *ServerInterfaceWrapper) TransferAsset(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "id" -------------
+	var id Id
+
+	err = runtime.BindStyledParameterWithOptions("simple", "id", r.PathValue("id"), &id, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "id", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.TransferAsset(w, r, id)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Invoke operation middleware
+func (siw *ServerInterfaceWrapper) Invoke(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params InvokeParams
+
+	// ------------- Optional query parameter "async" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "async", r.URL.Query(), &params.Async)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "async", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Invoke(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// InvokeBatch operation middleware
+func (siw *ServerInterfaceWrapper) InvokeBatch(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.InvokeBatch(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Query operation middleware
+func (siw *ServerInterfaceWrapper) Query(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params QueryParams
+
+	// ------------- Required query parameter "channelid" -------------
+
+	if paramValue := r.URL.Query().Get("channelid"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "channelid"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "channelid", r.URL.Query(), &params.Channelid)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "channelid", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "chaincodeid" -------------
+
+	if paramValue := r.URL.Query().Get("chaincodeid"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "chaincodeid"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "chaincodeid", r.URL.Query(), &params.Chaincodeid)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "chaincodeid", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "function" -------------
+
+	if paramValue := r.URL.Query().Get("function"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "function"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "function", r.URL.Query(), &params.Function)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "function", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "args" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "args", r.URL.Query(), &params.Args)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "args", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Query(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// Evaluate operation middleware
+func (siw *ServerInterfaceWrapper) Evaluate(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.Evaluate(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTransaction operation middleware
+func (siw *ServerInterfaceWrapper) GetTransaction(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// ------------- Path parameter "txid" -------------
+	var txid string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "txid", r.PathValue("txid"), &txid, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "txid", Err: err})
+		return
+	}
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, ApiKeyScopes, []string{})
+
+	ctx = context.WithValue(ctx, BearerAuthScopes, []string{})
+
+	ctx = context.WithValue(ctx, AccessTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTransactionParams
+
+	// ------------- Optional query parameter "channel" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "channel", r.URL.Query(), &params.Channel)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "channel", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTransaction(w, r, txid, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{})
+}
+
+// ServeMux is an abstraction of http.ServeMux.
+type ServeMux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
+type StdHTTPServerOptions struct {
+	BaseURL          string
+	BaseRouter       ServeMux
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, m ServeMux) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{
+		BaseRouter: m,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, m ServeMux, baseURL string) http.Handler {
+	return HandlerWithOptions(si, StdHTTPServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: m,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.Handler {
+	m := options.BaseRouter
+
+	if m == nil {
+		m = http.NewServeMux()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	m.HandleFunc("POST "+options.BaseURL+"/assets", wrapper.CreateAsset)
+	m.HandleFunc("GET "+options.BaseURL+"/assets/{id}", wrapper.ReadAsset)
+	m.HandleFunc("PUT "+options.BaseURL+"/assets/{id}/owner", wrapper.TransferAsset)
+	m.HandleFunc("POST "+options.BaseURL+"/invoke", wrapper.Invoke)
+	m.HandleFunc("POST "+options.BaseURL+"/invoke/batch", wrapper.InvokeBatch)
+	m.HandleFunc("GET "+options.BaseURL+"/query", wrapper.Query)
+	m.HandleFunc("POST "+options.BaseURL+"/query", wrapper.Evaluate)
+	m.HandleFunc("GET "+options.BaseURL+"/transactions/{txid}", wrapper.GetTransaction)
+
+	return m
+}
+
+type ErrorResponseJSONResponse ErrorResponse
+
+type TooManyRequestsResponseHeaders struct {
+	RetryAfter int
+}
+type TooManyRequestsJSONResponse struct {
+	Body ErrorResponse
+
+	Headers TooManyRequestsResponseHeaders
+}
+
+type TransactionAcceptedResponseHeaders struct {
+	Location string
+}
+type TransactionAcceptedJSONResponse struct {
+	Body TransactionStatus
+
+	Headers TransactionAcceptedResponseHeaders
+}
+
+type TransactionSuccessJSONResponse TransactionResponse
+
+type CreateAssetRequestObject struct {
+	Body *CreateAssetJSONRequestBody
+}
+
+type CreateAssetResponseObject interface {
+	VisitCreateAssetResponse(w http.ResponseWriter) error
+}
+
+type CreateAsset201JSONResponse struct{ TransactionSuccessJSONResponse }
+
+func (response CreateAsset201JSONResponse) VisitCreateAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(201)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type CreateAsset429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response CreateAsset429JSONResponse) VisitCreateAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type CreateAssetdefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response CreateAssetdefaultJSONResponse) VisitCreateAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ReadAssetRequestObject struct {
+	Id Id `json:"id"`
+}
+
+type ReadAssetResponseObject interface {
+	VisitReadAssetResponse(w http.ResponseWriter) error
+}
+
+type ReadAsset200JSONResponse Asset
+
+func (response ReadAsset200JSONResponse) VisitReadAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ReadAsset429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response ReadAsset429JSONResponse) VisitReadAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type ReadAssetdefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response ReadAssetdefaultJSONResponse) VisitReadAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type TransferAssetRequestObject struct {
+	Id   Id `json:"id"`
+	Body *TransferAssetJSONRequestBody
+}
+
+type TransferAssetResponseObject interface {
+	VisitTransferAssetResponse(w http.ResponseWriter) error
+}
+
+type TransferAsset200JSONResponse struct{ TransactionSuccessJSONResponse }
+
+func (response TransferAsset200JSONResponse) VisitTransferAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type TransferAsset429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response TransferAsset429JSONResponse) VisitTransferAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type TransferAssetdefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response TransferAssetdefaultJSONResponse) VisitTransferAssetResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type InvokeRequestObject struct {
+	Params       InvokeParams
+	JSONBody     *InvokeJSONRequestBody
+	FormdataBody *InvokeFormdataRequestBody
+}
+
+type InvokeResponseObject interface {
+	VisitInvokeResponse(w http.ResponseWriter) error
+}
+
+type Invoke200JSONResponse struct{ TransactionSuccessJSONResponse }
+
+func (response Invoke200JSONResponse) VisitInvokeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Invoke202JSONResponse struct {
+	TransactionAcceptedJSONResponse
+}
+
+func (response Invoke202JSONResponse) VisitInvokeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", fmt.Sprint(response.Headers.Location))
+	w.WriteHeader(202)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type Invoke429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response Invoke429JSONResponse) VisitInvokeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type InvokedefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response InvokedefaultJSONResponse) VisitInvokeResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type InvokeBatchRequestObject struct {
+	Body *InvokeBatchJSONRequestBody
+}
+
+type InvokeBatchResponseObject interface {
+	VisitInvokeBatchResponse(w http.ResponseWriter) error
+}
+
+type InvokeBatch200JSONResponse BatchResponse
+
+func (response InvokeBatch200JSONResponse) VisitInvokeBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type InvokeBatchdefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response InvokeBatchdefaultJSONResponse) VisitInvokeBatchResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type QueryRequestObject struct {
+	Params QueryParams
+}
+
+type QueryResponseObject interface {
+	VisitQueryResponse(w http.ResponseWriter) error
+}
+
+type Query200JSONResponse struct{ TransactionSuccessJSONResponse }
+
+func (response Query200JSONResponse) VisitQueryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Query429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response Query429JSONResponse) VisitQueryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type QuerydefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response QuerydefaultJSONResponse) VisitQueryResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type EvaluateRequestObject struct {
+	Body *EvaluateJSONRequestBody
+}
+
+type EvaluateResponseObject interface {
+	VisitEvaluateResponse(w http.ResponseWriter) error
+}
+
+type Evaluate200JSONResponse struct{ TransactionSuccessJSONResponse }
+
+func (response Evaluate200JSONResponse) VisitEvaluateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type Evaluate429JSONResponse struct{ TooManyRequestsJSONResponse }
+
+func (response Evaluate429JSONResponse) VisitEvaluateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type EvaluatedefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response EvaluatedefaultJSONResponse) VisitEvaluateResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+type GetTransactionRequestObject struct {
+	Txid   string `json:"txid"`
+	Params GetTransactionParams
+}
+
+type GetTransactionResponseObject interface {
+	VisitGetTransactionResponse(w http.ResponseWriter) error
+}
+
+type GetTransaction200JSONResponse TransactionStatus
+
+func (response GetTransaction200JSONResponse) VisitGetTransactionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransactiondefaultJSONResponse struct {
+	Body       ErrorResponse
+	StatusCode int
+}
+
+func (response GetTransactiondefaultJSONResponse) VisitGetTransactionResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.StatusCode)
+
+	return json.NewEncoder(w).Encode(response.Body)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Create an asset
+	// (POST /assets)
+	CreateAsset(ctx context.Context, request CreateAssetRequestObject) (CreateAssetResponseObject, error)
+	// Read an asset
+	// (GET /assets/{id})
+	ReadAsset(ctx context.Context, request ReadAssetRequestObject) (ReadAssetResponseObject, error)
+	// Transfer an asset to a new owner
+	// (PUT /assets/{id}/owner)
+	TransferAsset(ctx context.Context, request TransferAssetRequestObject) (TransferAssetResponseObject, error)
+	// Submit a transaction and wait for it to commit
+	// (POST /invoke)
+	Invoke(ctx context.Context, request InvokeRequestObject) (InvokeResponseObject, error)
+	// Submit several transactions and wait for them to commit
+	// (POST /invoke/batch)
+	InvokeBatch(ctx context.Context, request InvokeBatchRequestObject) (InvokeBatchResponseObject, error)
+	// Evaluate a transaction function
+	// (GET /query)
+	Query(ctx context.Context, request QueryRequestObject) (QueryResponseObject, error)
+	// Evaluate a transaction function
+	// (POST /query)
+	Evaluate(ctx context.Context, request EvaluateRequestObject) (EvaluateResponseObject, error)
+	// Get the status of a transaction submitted with async=true
+	// (GET /transactions/{txid})
+	GetTransaction(ctx context.Context, request GetTransactionRequestObject) (GetTransactionResponseObject, error)
+}
+
+type StrictHandlerFunc = strictnethttp.StrictHTTPHandlerFunc
+type StrictMiddlewareFunc = strictnethttp.StrictHTTPMiddlewareFunc
+
+type StrictHTTPServerOptions struct {
+	RequestErrorHandlerFunc  func(w http.ResponseWriter, r *http.Request, err error)
+	ResponseErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		},
+		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		},
+	}}
+}
+
+func NewStrictHandlerWithOptions(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc, options StrictHTTPServerOptions) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares, options: options}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+	options     StrictHTTPServerOptions
+}
+
+// CreateAsset operation middleware
+func (sh *strictHandler) CreateAsset(w http.ResponseWriter, r *http.Request) {
+	var request CreateAssetRequestObject
+
+	var body CreateAssetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateAsset(ctx, request.(CreateAssetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateAsset")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(CreateAssetResponseObject); ok {
+		if err := validResponse.VisitCreateAssetResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ReadAsset operation middleware
+func (sh *strictHandler) ReadAsset(w http.ResponseWriter, r *http.Request, id Id) {
+	var request ReadAssetRequestObject
+
+	request.Id = id
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.ReadAsset(ctx, request.(ReadAssetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ReadAsset")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(ReadAssetResponseObject); ok {
+		if err := validResponse.VisitReadAssetResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// TransferAsset operation middleware
+func (sh *strictHandler) TransferAsset(w http.ResponseWriter, r *http.Request, id Id) {
+	var request TransferAssetRequestObject
+
+	request.Id = id
+
+	var body TransferAssetJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.TransferAsset(ctx, request.(TransferAssetRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "TransferAsset")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(TransferAssetResponseObject); ok {
+		if err := validResponse.VisitTransferAssetResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Invoke operation middleware
+func (sh *strictHandler) Invoke(w http.ResponseWriter, r *http.Request, params InvokeParams) {
+	var request InvokeRequestObject
+
+	request.Params = params
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+
+		var body InvokeJSONRequestBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+			return
+		}
+		request.JSONBody = &body
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := r.ParseForm(); err != nil {
+			sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode formdata: %w", err))
+			return
+		}
+		var body InvokeFormdataRequestBody
+		if err := runtime.BindForm(&body, r.Form, nil, nil); err != nil {
+			sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't bind formdata: %w", err))
+			return
+		}
+		request.FormdataBody = &body
+	}
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Invoke(ctx, request.(InvokeRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Invoke")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(InvokeResponseObject); ok {
+		if err := validResponse.VisitInvokeResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// InvokeBatch operation middleware
+func (sh *strictHandler) InvokeBatch(w http.ResponseWriter, r *http.Request) {
+	var request InvokeBatchRequestObject
+
+	var body InvokeBatchJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.InvokeBatch(ctx, request.(InvokeBatchRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "InvokeBatch")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(InvokeBatchResponseObject); ok {
+		if err := validResponse.VisitInvokeBatchResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Query operation middleware
+func (sh *strictHandler) Query(w http.ResponseWriter, r *http.Request, params QueryParams) {
+	var request QueryRequestObject
+
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Query(ctx, request.(QueryRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Query")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(QueryResponseObject); ok {
+		if err := validResponse.VisitQueryResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// Evaluate operation middleware
+func (sh *strictHandler) Evaluate(w http.ResponseWriter, r *http.Request) {
+	var request EvaluateRequestObject
+
+	var body EvaluateJSONRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		sh.options.RequestErrorHandlerFunc(w, r, fmt.Errorf("can't decode JSON body: %w", err))
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.Evaluate(ctx, request.(EvaluateRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "Evaluate")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(EvaluateResponseObject); ok {
+		if err := validResponse.VisitEvaluateResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetTransaction operation middleware
+func (sh *strictHandler) GetTransaction(w http.ResponseWriter, r *http.Request, txid string, params GetTransactionParams) {
+	var request GetTransactionRequestObject
+
+	request.Txid = txid
+	request.Params = params
+
+	handler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransaction(ctx, request.(GetTransactionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransaction")
+	}
+
+	response, err := handler(r.Context(), w, r, request)
+
+	if err != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, err)
+	} else if validResponse, ok := response.(GetTransactionResponseObject); ok {
+		if err := validResponse.VisitGetTransactionResponse(w); err != nil {
+			sh.options.ResponseErrorHandlerFunc(w, r, err)
+		}
+	} else if response != nil {
+		sh.options.ResponseErrorHandlerFunc(w, r, fmt.Errorf("unexpected response type: %T", response))
+	}
+}
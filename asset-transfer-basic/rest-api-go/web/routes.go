@@ -0,0 +1,131 @@
+package web
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	oapimiddleware "github.com/oapi-codegen/nethttp-middleware"
+)
+
+// The routes, models and strict server in routes.gen.go are generated from openapi.yaml:
+//   oapi-codegen -config oapi-server.yaml web/openapi.yaml
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// loadSpec parses the OpenAPI specification of the REST API.
+func loadSpec() (*openapi3.T, error) {
+	spec, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	if err != nil {
+		return nil, fmt.Errorf("error loading OpenAPI spec: %w", err)
+	}
+	return spec, nil
+}
+
+var _ StrictServerInterface = (*OrgSetup)(nil)
+
+// Handler returns the HTTP handler of the REST API described by openapi.yaml.
+// Requests are authenticated, validated against the specification and limited by the Limiter before they reach
+// the gateway.
+// The specification itself is served at /openapi.json and /openapi.yaml, and the /healthz and /readyz probes
+// and /metrics are served without authentication.
+func (setup *OrgSetup) Handler() (http.Handler, error) {
+	if setup.transactions == nil {
+		setup.transactions = newTransactionTracker()
+	}
+	if setup.lifecycle == nil {
+		setup.lifecycle = newLifecycle()
+	}
+	setup.boundedRules = setup.metricRules()
+
+	spec, err := loadSpec()
+	if err != nil {
+		return nil, err
+	}
+	validationSpec, err := loadSpec()
+	if err != nil {
+		return nil, err
+	}
+	validator := oapimiddleware.OapiRequestValidatorWithOptions(validationSpec, &oapimiddleware.Options{
+		DoNotValidateServers: true,
+		// Requests are authenticated by OrgSetup.Authenticator; the security schemes only document it
+		Options: openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		ErrorHandlerWithOpts: func(ctx context.Context, err error, w http.ResponseWriter, r *http.Request, opts oapimiddleware.ErrorHandlerOpts) {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeError(w, r, bodyTooLarge(maxBytesErr.Limit))
+				return
+			}
+			writeError(w, r, &requestError{status: opts.StatusCode, code: "BAD_REQUEST", err: validationError(err)})
+		},
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	})
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	})
+	if setup.Metrics != nil {
+		mux.Handle("GET /metrics", setup.Metrics.Handler())
+	}
+	// Probes and event streams are excluded from the generated routes, see oapi-server.yaml
+	mux.HandleFunc("GET /healthz", setup.Healthz)
+	mux.HandleFunc("GET /readyz", setup.Readyz)
+	mux.Handle("GET /events/chaincode", setup.authenticate(validator(http.HandlerFunc(setup.ChaincodeEvents))))
+	mux.Handle("GET /events/blocks", setup.authenticate(validator(http.HandlerFunc(setup.BlockEvents))))
+
+	strictHandler := NewStrictHandlerWithOptions(setup, []StrictMiddlewareFunc{nameSpan, setup.extendBatchWriteDeadline, rejectUnknownFormFields}, StrictHTTPServerOptions{
+		RequestErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeError(w, r, requestBodyError(err))
+		},
+		ResponseErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeError(w, r, err)
+		},
+	})
+	return setup.Limiter.limitBody(HandlerWithOptions(strictHandler, StdHTTPServerOptions{
+		BaseRouter: mux,
+		// The last middleware runs first
+		Middlewares: []MiddlewareFunc{validator, setup.authenticate},
+		ErrorHandlerFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeError(w, r, badRequest("%s", err))
+		},
+	})), nil
+}
+
+// validationError shortens an OpenAPI request validation error to the offending parameter or field and the reason.
+func validationError(err error) error {
+	var requestErr *openapi3filter.RequestError
+	if !errors.As(err, &requestErr) {
+		return err
+	}
+
+	var location string
+	switch {
+	case requestErr.Parameter != nil:
+		location = fmt.Sprintf("parameter %s", requestErr.Parameter.Name)
+	case requestErr.RequestBody != nil:
+		location = "request body"
+	default:
+		return errors.New(requestErr.Reason)
+	}
+
+	var schemaErr *openapi3.SchemaError
+	switch {
+	case errors.As(err, &schemaErr) && len(schemaErr.JSONPointer()) > 0:
+		return fmt.Errorf("%s: %s: %s", location, strings.Join(schemaErr.JSONPointer(), "."), schemaErr.Reason)
+	case errors.As(err, &schemaErr):
+		return fmt.Errorf("%s: %s", location, schemaErr.Reason)
+	case requestErr.Err != nil:
+		return fmt.Errorf("%s: %w", location, requestErr.Err)
+	}
+	return fmt.Errorf("%s: %s", location, requestErr.Reason)
+}
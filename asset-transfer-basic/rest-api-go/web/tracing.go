@@ -0,0 +1,159 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the spans of the REST server, with the global tracer provider set by the application.
+var tracer = otel.Tracer("rest-api-go/web")
+
+// NewTracerProvider creates a tracer provider that samples every trace, so that logs carry trace IDs, and sends the
+// spans to the exporter, if any. Shut the provider down to export the remaining spans.
+func NewTracerProvider(serviceName string, exporter sdktrace.SpanExporter) (*sdktrace.TracerProvider, error) {
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(serviceResource)}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...), nil
+}
+
+// observe runs one stage of a transaction in a span, and records its outcome and latency in the metrics.
+func (setup *OrgSetup) observe(ctx context.Context, stage string, labels transactionLabels, run func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, stage, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("fabric.org", setup.OrgName),
+		attribute.String("fabric.channel", labels.channelID),
+		attribute.String("fabric.chaincode", labels.chaincodeID),
+		attribute.String("fabric.function", labels.function),
+	))
+	defer span.End()
+
+	start := time.Now()
+	err := run(ctx)
+	setup.Metrics.observe(setup.OrgName, stage, setup.metricLabels(labels), time.Since(start), err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, newErrorBody(err).Code)
+	}
+	return err
+}
+
+// traceRequests runs each request in a server span, continuing the trace of the W3C traceparent header if there is
+// one, and logs the outcome of the request with its trace ID.
+func traceRequests(next http.Handler) http.Handler {
+	propagator := propagation.TraceContext{}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, "HTTP "+r.Method, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		))
+		defer span.End()
+		// Let clients find the trace of their request
+		propagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(recorder.status))
+		}
+		slog.InfoContext(ctx, "Request completed",
+			"method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", time.Since(start))
+	})
+}
+
+// nameSpan names the request span after the OpenAPI operation.
+func nameSpan(f StrictHandlerFunc, operationID string) StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		trace.SpanFromContext(ctx).SetName(operationID)
+		return f(ctx, w, r, request)
+	}
+}
+
+// statusRecorder records the status of a response. It passes on flushes for event streams and hijacking for
+// WebSockets.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *statusRecorder) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	w.status, w.wroteHeader = http.StatusSwitchingProtocols, true
+	return hijacker.Hijack()
+}
+
+// Unwrap lets http.ResponseController reach the underlying response, for example to set deadlines.
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NewTraceLogHandler returns a log handler that adds the trace and span IDs of the log context to each record,
+// so that logs can be matched with traces.
+func NewTraceLogHandler(handler slog.Handler) slog.Handler {
+	return traceLogHandler{handler}
+}
+
+type traceLogHandler struct {
+	slog.Handler
+}
+
+func (h traceLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h traceLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return traceLogHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h traceLogHandler) WithGroup(name string) slog.Handler {
+	return traceLogHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,124 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// collectorStub is an OTLP/HTTP trace collector that keeps the names of the spans it receives by trace ID.
+type collectorStub struct {
+	mu    sync.Mutex
+	spans map[string][]string
+}
+
+func newCollectorStub(t *testing.T) (*collectorStub, *httptest.Server) {
+	t.Helper()
+	collector := &collectorStub{spans: map[string][]string{}}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil || r.URL.Path != "/v1/traces" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		request := &collectortrace.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		collector.mu.Lock()
+		for _, resourceSpans := range request.GetResourceSpans() {
+			for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+				for _, span := range scopeSpans.GetSpans() {
+					traceID := hex.EncodeToString(span.GetTraceId())
+					collector.spans[traceID] = append(collector.spans[traceID], span.GetName())
+				}
+			}
+		}
+		collector.mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		response, _ := proto.Marshal(&collectortrace.ExportTraceServiceResponse{})
+		w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return collector, server
+}
+
+func TestRequestsAreTracedAndLogged(t *testing.T) {
+	collector, server := newCollectorStub(t)
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(server.URL+"/v1/traces"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracerProvider, err := NewTracerProvider("rest-api-go-test", exporter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracerProvider)
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	var logs bytes.Buffer
+	previousLogger := slog.Default()
+	slog.SetDefault(slog.New(NewTraceLogHandler(slog.NewJSONHandler(&logs, nil))))
+	t.Cleanup(func() { slog.SetDefault(previousLogger) })
+
+	orgs, err := NewOrgs(&OrgSetup{OrgName: "Org1", DefaultIdentity: "User1", Authenticator: IdentityHeader{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, err := orgs.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	r := httptest.NewRequest(http.MethodGet, "/transactions/tx1", nil)
+	r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+	if traceparent := w.Header().Get("traceparent"); len(traceparent) < 35 || traceparent[3:35] != traceID {
+		t.Errorf("expected the response to continue trace %s, got traceparent %q", traceID, traceparent)
+	}
+
+	if err := tracerProvider.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	collector.mu.Lock()
+	spans := collector.spans[traceID]
+	collector.mu.Unlock()
+	if len(spans) != 1 || spans[0] != "GetTransaction" {
+		t.Errorf("expected a GetTransaction span in trace %s, got %v", traceID, spans)
+	}
+
+	records := 0
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		if record["trace_id"] != traceID {
+			t.Errorf("expected log record in trace %s, got %v", traceID, record)
+		}
+		records++
+	}
+	if records == 0 {
+		t.Error("expected request logs")
+	}
+}
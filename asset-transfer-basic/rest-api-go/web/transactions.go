@@ -0,0 +1,191 @@
+package web
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// transactionRetention is how long the status of a completed asynchronous transaction remains available.
+const transactionRetention = 10 * time.Minute
+
+// ledgerLookupTimeout bounds the lookup of a transaction on the ledger. The gateway waits for transactions that have
+// not committed yet, so a transaction that is still not on the ledger when the lookup times out is not found.
+const ledgerLookupTimeout = 2 * time.Second
+
+// transactionTracker records the outcome of transactions submitted asynchronously.
+type transactionTracker struct {
+	mu           sync.Mutex
+	transactions map[string]*trackedTransaction
+	now          func() time.Time
+}
+
+type trackedTransaction struct {
+	principal string
+	status    TransactionStatus
+	completed time.Time
+}
+
+func newTransactionTracker() *transactionTracker {
+	return &transactionTracker{transactions: make(map[string]*trackedTransaction), now: time.Now}
+}
+
+// track records a pending transaction submitted by principal and waits in the background for its commit status.
+func (t *transactionTracker) track(principal string, txID string, result []byte, status func() (*client.Status, error)) TransactionStatus {
+	pending := TransactionStatus{TxId: txID, Status: TransactionPending, Result: chaincodeResult(result)}
+
+	t.mu.Lock()
+	t.prune()
+	t.transactions[txID] = &trackedTransaction{principal: principal, status: pending}
+	t.mu.Unlock()
+
+	go func() {
+		commitStatus, err := status()
+		t.complete(txID, commitStatus, err)
+	}()
+	return pending
+}
+
+func (t *transactionTracker) complete(txID string, commitStatus *client.Status, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transaction, ok := t.transactions[txID]
+	if !ok {
+		return
+	}
+	transaction.completed = t.now()
+	status := &transaction.status
+	switch {
+	case err != nil:
+		slog.Warn("Failed to get commit status", "txId", txID, "error", err)
+		status.Status = TransactionUnknown
+		status.Error = err.Error()
+	case commitStatus.Successful:
+		status.Status = TransactionCommitted
+	default:
+		status.Status = TransactionFailed
+	}
+	if commitStatus != nil {
+		status.BlockNumber = commitStatus.BlockNumber
+		status.ValidationCode = commitStatus.Code.String()
+	}
+}
+
+// get returns the status of a transaction submitted by principal.
+func (t *transactionTracker) get(principal string, txID string) (TransactionStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune()
+	transaction, ok := t.transactions[txID]
+	// Other principals cannot tell whether the transaction exists
+	if !ok || transaction.principal != principal {
+		return TransactionStatus{}, false
+	}
+	return transaction.status, true
+}
+
+// prune forgets transactions that completed more than transactionRetention ago. The caller holds t.mu.
+func (t *transactionTracker) prune() {
+	cutoff := t.now().Add(-transactionRetention)
+	for txID, transaction := range t.transactions {
+		if !transaction.completed.IsZero() && transaction.completed.Before(cutoff) {
+			delete(t.transactions, txID)
+		}
+	}
+}
+
+// GetTransaction reports the status of a transaction submitted by the request's principal with async=true, or else
+// the commit status of the transaction on the ledger.
+func (setup *OrgSetup) GetTransaction(ctx context.Context, request GetTransactionRequestObject) (GetTransactionResponseObject, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, &requestError{status: http.StatusUnauthorized, code: "UNAUTHENTICATED", err: errors.New("no principal")}
+	}
+	if status, ok := setup.transactions.get(principal.Name, request.Txid); ok {
+		return GetTransaction200JSONResponse(status), nil
+	}
+	status, err := setup.ledgerTransaction(ctx, cmp.Or(request.Params.Channel, setup.ChannelID), request.Txid)
+	if err != nil {
+		return nil, err
+	}
+	return GetTransaction200JSONResponse(status), nil
+}
+
+// ledgerTransaction looks up the commit status of a transaction on the ledger of a channel, for transactions submitted
+// by another server, or forgotten by this one. The lookup is authorized like a call to GetTransactionByID on qscc.
+func (setup *OrgSetup) ledgerTransaction(ctx context.Context, channelID, txID string) (TransactionStatus, error) {
+	notFound := &requestError{status: http.StatusNotFound, code: "NOT_FOUND", err: fmt.Errorf("transaction %s not found", txID)}
+	if channelID == "" {
+		return TransactionStatus{}, notFound
+	}
+	// Principals that may not read the ledger cannot tell whether the transaction exists
+	if err := setup.authorize(ctx, channelID, "qscc", "GetTransactionByID"); err != nil {
+		return TransactionStatus{}, notFound
+	}
+	gw, err := setup.requestGateway(ctx)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+	commit, err := newLedgerCommit(gw, channelID, txID)
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+
+	var commitStatus *client.Status
+	labels := transactionLabels{channelID: channelID, chaincodeID: "qscc", function: "GetTransactionByID"}
+	err = setup.observe(ctx, stageCommit, labels, func(ctx context.Context) (err error) {
+		ctx, cancel := context.WithTimeout(ctx, ledgerLookupTimeout)
+		defer cancel()
+		commitStatus, err = commit.StatusWithContext(ctx)
+		if status.Code(err) == codes.DeadlineExceeded || errors.Is(err, context.DeadlineExceeded) {
+			return notFound
+		}
+		return err
+	})
+	if err != nil {
+		return TransactionStatus{}, err
+	}
+
+	result := TransactionStatus{
+		TxId:           txID,
+		Status:         TransactionFailed,
+		BlockNumber:    commitStatus.BlockNumber,
+		ValidationCode: commitStatus.Code.String(),
+	}
+	if commitStatus.Successful {
+		result.Status = TransactionCommitted
+	}
+	return result, nil
+}
+
+// newLedgerCommit creates a Commit that gets the status of any transaction on a channel, signed by the gateway's
+// identity.
+func newLedgerCommit(gw *client.Gateway, channelID, txID string) (*client.Commit, error) {
+	creator, err := proto.Marshal(&msp.SerializedIdentity{Mspid: gw.Identity().MspID(), IdBytes: gw.Identity().Credentials()})
+	if err != nil {
+		return nil, err
+	}
+	request, err := proto.Marshal(&gateway.CommitStatusRequest{TransactionId: txID, ChannelId: channelID, Identity: creator})
+	if err != nil {
+		return nil, err
+	}
+	signedRequest, err := proto.Marshal(&gateway.SignedCommitStatusRequest{Request: request})
+	if err != nil {
+		return nil, err
+	}
+	return gw.NewCommit(signedRequest)
+}
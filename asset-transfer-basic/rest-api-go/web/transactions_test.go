@@ -0,0 +1,138 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// waitForStatus polls the tracker until the transaction is no longer pending.
+func waitForStatus(t *testing.T, tracker *transactionTracker, principal, txID string) TransactionStatus {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		status, ok := tracker.get(principal, txID)
+		if !ok {
+			t.Fatalf("transaction %s not found", txID)
+		}
+		if status.Status != TransactionPending {
+			return status
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("transaction %s still pending", txID)
+	return TransactionStatus{}
+}
+
+func TestTransactionTracker(t *testing.T) {
+	tracker := newTransactionTracker()
+
+	t.Run("committed", func(t *testing.T) {
+		commit := make(chan struct{})
+		pending := tracker.track("alice", "tx1", []byte(`{"ID":"asset1"}`), func() (*client.Status, error) {
+			<-commit
+			return &client.Status{TransactionID: "tx1", Successful: true, Code: peer.TxValidationCode_VALID, BlockNumber: 7}, nil
+		})
+		if pending.Status != TransactionPending {
+			t.Errorf("expected pending, got %s", pending.Status)
+		}
+		if status, _ := tracker.get("alice", "tx1"); status.Status != TransactionPending {
+			t.Errorf("expected pending before commit, got %s", status.Status)
+		}
+
+		close(commit)
+		status := waitForStatus(t, tracker, "alice", "tx1")
+		if status.Status != TransactionCommitted || status.BlockNumber != 7 || status.ValidationCode != "VALID" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("other principal", func(t *testing.T) {
+		if _, ok := tracker.get("bob", "tx1"); ok {
+			t.Error("expected transaction of alice to be hidden from bob")
+		}
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		tracker.track("alice", "tx2", nil, func() (*client.Status, error) {
+			return &client.Status{TransactionID: "tx2", Code: peer.TxValidationCode_MVCC_READ_CONFLICT, BlockNumber: 8}, nil
+		})
+		status := waitForStatus(t, tracker, "alice", "tx2")
+		if status.Status != TransactionFailed || status.ValidationCode != "MVCC_READ_CONFLICT" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		tracker.track("alice", "tx3", nil, func() (*client.Status, error) {
+			return nil, errors.New("commit status timeout")
+		})
+		status := waitForStatus(t, tracker, "alice", "tx3")
+		if status.Status != TransactionUnknown || status.Error == "" {
+			t.Errorf("unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		tracker.now = func() time.Time { return time.Now().Add(transactionRetention + time.Minute) }
+		if _, ok := tracker.get("alice", "tx1"); ok {
+			t.Error("expected completed transaction to be forgotten after the retention period")
+		}
+	})
+}
+
+func TestHandlerReportsUnknownTransactions(t *testing.T) {
+	handler, err := (&OrgSetup{DefaultIdentity: "User1", Authenticator: IdentityHeader{}}).Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions/tx1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlerLooksUpTransactionsOnLedger(t *testing.T) {
+	setup, _ := newFakeGatewaySetup(t)
+	setup.ChannelID = "mychannel"
+	handler, err := setup.Handler()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions/tx1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var status TransactionStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	expected := TransactionStatus{TxId: "tx1", Status: TransactionCommitted, BlockNumber: 5, ValidationCode: "VALID"}
+	if !reflect.DeepEqual(status, expected) {
+		t.Errorf("expected %+v, got %+v", expected, status)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions/tx2?channel=other", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Principals that may not read the ledger are told nothing
+	setup.Authorizer = Policy{}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/transactions/tx1", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sdk-go/service"
+)
+
+// errUsage marks command line errors, which exit with status 2
+var errUsage = errors.New("invalid usage")
+
+// connectMode is how much of the gateway connection a command needs
+type connectMode int
+
+const (
+	connectGateway  connectMode = iota // Gateway connection with an in-process signer
+	connectUnsigned                    // Gateway connection with the identity only, for offline signing
+	connectNone                        // Local credentials only, no network access
+)
+
+// command is a CLI subcommand operating on the asset-transfer-basic chaincode
+type command struct {
+	summary  string
+	args     []string
+	variadic bool // Accept further arguments after args
+	connect  connectMode
+	run      func(ctx context.Context, s *session, args []string) (any, error)
+}
+
+func (c command) usage() string {
+	names := make([]string, len(c.args))
+	for i, arg := range c.args {
+		names[i] = "<" + arg + ">"
+	}
+	if c.variadic {
+		names = append(names, "[args...]")
+	}
+	return strings.Join(names, " ")
+}
+
+// accepts reports whether the command takes count arguments
+func (c command) accepts(count int) bool {
+	if c.variadic {
+		return count >= len(c.args)
+	}
+	return count == len(c.args)
+}
+
+var commands = map[string]command{
+	"init": {
+		summary: "initialize the ledger with sample assets",
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			return waitForCommit(ctx, s, nil, s.assets.InitLedgerAsync)
+		},
+	},
+	"create": {
+		summary: "create a new asset",
+		args:    []string{"id", "color", "size", "owner", "value"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			asset, err := parseAsset(args)
+			if err != nil {
+				return nil, err
+			}
+			return waitForCommit(ctx, s, asset, func(ctx context.Context) (*service.Commit, error) {
+				return s.assets.CreateAssetAsync(ctx, asset)
+			})
+		},
+	},
+	"read": {
+		summary: "read an asset",
+		args:    []string{"id"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			return s.assets.ReadAsset(ctx, args[0])
+		},
+	},
+	"update": {
+		summary: "replace an existing asset",
+		args:    []string{"id", "color", "size", "owner", "value"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			asset, err := parseAsset(args)
+			if err != nil {
+				return nil, err
+			}
+			return waitForCommit(ctx, s, asset, func(ctx context.Context) (*service.Commit, error) {
+				return s.assets.UpdateAssetAsync(ctx, asset)
+			})
+		},
+	},
+	"delete": {
+		summary: "delete an asset",
+		args:    []string{"id"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			return waitForCommit(ctx, s, nil, func(ctx context.Context) (*service.Commit, error) {
+				return s.assets.DeleteAssetAsync(ctx, args[0])
+			})
+		},
+	},
+	"transfer": {
+		summary: "transfer an asset to a new owner",
+		args:    []string{"id", "new-owner"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			var oldOwner string
+			tx, err := waitForCommit(ctx, s, nil, func(ctx context.Context) (commit *service.Commit, err error) {
+				oldOwner, commit, err = s.assets.TransferAssetAsync(ctx, args[0], args[1])
+				return commit, err
+			})
+			if err != nil {
+				return nil, err
+			}
+			tx.Result = transfer{ID: args[0], OldOwner: oldOwner, NewOwner: args[1]}
+			return tx, nil
+		},
+	},
+	"exists": {
+		summary: "check whether an asset exists",
+		args:    []string{"id"},
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			exists, err := s.assets.AssetExists(ctx, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return existence{ID: args[0], Exists: exists}, nil
+		},
+	},
+	"list": {
+		summary: "list all assets",
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			all, err := s.assets.GetAllAssets(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if all == nil {
+				all = []service.Asset{}
+			}
+			return all, nil
+		},
+	},
+}
+
+// commandNames returns the subcommand names in alphabetical order
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// waitForCommit submits a transaction with send, waits for it to commit and reports where it landed.
+// A transaction that fails validation with a read conflict is submitted again with send.
+func waitForCommit(ctx context.Context, s *session, result any, send func(ctx context.Context) (*service.Commit, error)) (*transaction, error) {
+	status, err := s.assets.Resubmit(ctx, send)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction{
+		TxID:           status.TransactionID,
+		BlockNumber:    status.BlockNumber,
+		ValidationCode: status.Code.String(),
+		Result:         result,
+	}, nil
+}
+
+// parseAsset builds an asset from the id, color, size, owner and value arguments
+func parseAsset(args []string) (service.Asset, error) {
+	size, err := strconv.Atoi(args[2])
+	if err != nil {
+		return service.Asset{}, fmt.Errorf("%w: size must be an integer: %s", errUsage, args[2])
+	}
+	value, err := strconv.Atoi(args[4])
+	if err != nil {
+		return service.Asset{}, fmt.Errorf("%w: value must be an integer: %s", errUsage, args[4])
+	}
+	return service.Asset{
+		ID:             args[0],
+		Color:          args[1],
+		Size:           size,
+		Owner:          args[3],
+		AppraisedValue: value,
+	}, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"sdk-go/network"
+	"sdk-go/service"
+)
+
+// session holds the connections opened for one CLI invocation
+type session struct {
+	connection  *network.ConnectionPool
+	credentials *network.Credentials
+	gateway     *client.Gateway
+	assets      *service.AssetService
+	assumeYes   bool // Do not ask for confirmation
+}
+
+// connect loads the connection profile and, depending on mode, verifies the credentials
+// and connects to the gateway
+func connect(opts options, mode connectMode) (*session, error) {
+	profile, err := loadProfile(opts.profile)
+	if err != nil {
+		return nil, err
+	}
+	target, err := profile.Select(opts.org, opts.peer, opts.user)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &session{assumeYes: opts.yes}
+	if mode == connectNone {
+		// Offline signing on an air-gapped machine only needs the local key
+		s.credentials, err = network.LoadCredentials(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load credentials: %w", err)
+		}
+		return s, nil
+	}
+	fmt.Fprintf(os.Stderr, "Connecting as %s@%s via %s\n", target.UserName, target.Org, target.PeerName)
+
+	// Every gateway peer in the profile joins the pool, so a peer restart fails over
+	// to another peer instead of taking the CLI down
+	peers, err := profile.GatewayPeers(target)
+	if err != nil {
+		return nil, err
+	}
+	s.connection, err = network.NewConnectionPool(peers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection: %w", err)
+	}
+
+	connectOptions := []client.ConnectOption{
+		client.WithHash(hash.SHA256),
+		client.WithClientConnection(s.connection),
+		client.WithEvaluateTimeout(5 * time.Second),
+		client.WithEndorseTimeout(15 * time.Second),
+		client.WithSubmitTimeout(5 * time.Second),
+		client.WithCommitStatusTimeout(1 * time.Minute),
+	}
+
+	var id identity.Identity
+	if mode == connectUnsigned {
+		// The private key stays on the signing machine; only the certificate is needed here
+		id, err = network.NewIdentity(target)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to load identity: %w", err)
+		}
+	} else {
+		s.credentials, err = network.LoadCredentials(target)
+		if err != nil {
+			s.Close()
+			return nil, fmt.Errorf("failed to load credentials: %w", err)
+		}
+		id = s.credentials.Identity
+		connectOptions = append(connectOptions, client.WithSign(s.credentials.Sign))
+	}
+
+	s.gateway, err = client.Connect(id, connectOptions...)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to connect to gateway: %w", err)
+	}
+
+	// Progress messages go to stderr so that stdout only carries the command result
+	s.assets = service.NewAssetService(s.gateway,
+		service.WithChannel(opts.channel),
+		service.WithChaincode(opts.chaincode),
+		service.WithOutput(os.Stderr),
+	)
+	return s, nil
+}
+
+func loadProfile(path string) (*network.Profile, error) {
+	if path != "" {
+		return network.LoadProfile(path)
+	}
+	return network.ProfileFromEnv(), nil
+}
+
+// Close releases the gateway, signer and gRPC connection
+func (s *session) Close() {
+	if s.gateway != nil {
+		s.gateway.Close()
+	}
+	if s.credentials != nil {
+		s.credentials.Close()
+	}
+	if s.connection != nil {
+		s.connection.Close()
+	}
+}
@@ -1,72 +1,93 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"os"
+	"os/signal"
+	"time"
 
-	"github.com/hyperledger/fabric-gateway/pkg/client"
-	"github.com/hyperledger/fabric-gateway/pkg/hash"
 	"sdk-go/network"
-	"sdk-go/service"
 )
 
-func main() {
-	fmt.Println("🚀 Starting Asset Management Client...")
-
-	// Create gRPC client connection
-	clientConnection, err := network.NewGrpcConnection()
-	if err != nil {
-		log.Fatalf("Failed to create gRPC connection: %v", err)
-	}
-	defer clientConnection.Close()
+// options holds the global command line flags
+type options struct {
+	profile   string
+	org       string
+	peer      string
+	user      string
+	channel   string
+	chaincode string
+	output    string
+	timeout   time.Duration
+	yes       bool
+}
 
-	// Create identity and signing
-	id := network.NewIdentity()
-	sign := network.NewSign()
-
-	// Create gateway connection
-	gateway, err := client.Connect(id, client.WithSign(sign), client.WithHash(hash.SHA256),
-		client.WithClientConnection(clientConnection))
-	if err != nil {
-		log.Fatalf("Failed to connect to gateway: %v", err)
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
 	}
-	defer gateway.Close()
+}
 
-	// Create asset service
-	assetService := service.NewAssetService(gateway)
+func run(args []string) error {
+	flags := flag.NewFlagSet("sdk-go", flag.ContinueOnError)
+	flags.Usage = func() { printUsage(flags) }
 
-	// Demonstrate chaincode operations
-	fmt.Println("\n📋 Asset Management Operations:")
+	opts := options{}
+	flags.StringVar(&opts.profile, "profile", os.Getenv(network.EnvProfile), "connection profile (YAML or JSON); defaults to $"+network.EnvProfile+" or environment variables")
+	flags.StringVar(&opts.org, "org", os.Getenv(network.EnvOrg), "organization in the connection profile")
+	flags.StringVar(&opts.peer, "peer", os.Getenv(network.EnvPeer), "gateway peer in the connection profile")
+	flags.StringVar(&opts.user, "user", os.Getenv(network.EnvUser), "user in the connection profile")
+	flags.StringVar(&opts.channel, "channel", "mychannel", "channel name")
+	flags.StringVar(&opts.chaincode, "chaincode", "basic", "chaincode name")
+	flags.StringVar(&opts.output, "output", outputTable, "output format: json or table")
+	flags.DurationVar(&opts.timeout, "timeout", 2*time.Minute, "overall timeout for the command")
+	flags.BoolVar(&opts.yes, "yes", false, "sign offline requests without asking for confirmation")
 
-	// Query all assets
-	allAssets, err := assetService.GetAllAssets()
-	if err != nil {
-		log.Printf("Failed to get all assets: %v", err)
-	} else {
-		fmt.Printf("All assets: %s\n", allAssets)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	if opts.output != outputTable && opts.output != outputJSON {
+		return fmt.Errorf("%w: unknown output format %s", errUsage, opts.output)
 	}
 
-	// Read a specific asset
-	asset, err := assetService.ReadAsset("asset1")
-	if err != nil {
-		log.Printf("Failed to read asset: %v", err)
-	} else {
-		fmt.Printf("Asset details: %s\n", asset)
+	if flags.NArg() == 0 {
+		printUsage(flags)
+		return fmt.Errorf("%w: missing command", errUsage)
+	}
+	name, commandArgs := flags.Arg(0), flags.Args()[1:]
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage(flags)
+		return fmt.Errorf("%w: unknown command %s", errUsage, name)
 	}
+	if !cmd.accepts(len(commandArgs)) {
+		return fmt.Errorf("%w: usage: %s %s", errUsage, name, cmd.usage())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
 
-	// Create a new asset
-	err = assetService.CreateAsset("asset7", "purple", "8", "Alice", "900")
+	session, err := connect(opts, cmd.connect)
 	if err != nil {
-		log.Printf("Failed to create asset: %v", err)
+		return err
 	}
+	defer session.Close()
 
-	// Query again to see new asset
-	allAssets, err = assetService.GetAllAssets()
+	result, err := cmd.run(ctx, session, commandArgs)
 	if err != nil {
-		log.Printf("Failed to get all assets: %v", err)
-	} else {
-		fmt.Printf("Updated assets: %s\n", allAssets)
+		return err
 	}
-
-	fmt.Println("\n✅ Operations completed!")
-}
\ No newline at end of file
+	return printResult(os.Stdout, opts.output, result)
+}
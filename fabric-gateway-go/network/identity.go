@@ -0,0 +1,196 @@
+package network // 身份加载 - 加载客户端证书和私钥，返回类型化错误而不是panic
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// 身份加载错误类型，可以通过errors.Is判断
+var (
+	ErrMissingCertificate = errors.New("certificate not found")
+	ErrMissingPrivateKey  = errors.New("private key not found")
+	ErrBadPEM             = errors.New("invalid PEM data")
+	ErrUnsupportedKeyType = errors.New("unsupported private key type")
+	ErrKeyMismatch        = errors.New("private key does not match certificate")
+	ErrMSPMismatch        = errors.New("certificate not issued by organization MSP")
+	ErrCertificateExpired = errors.New("certificate expired or not yet valid")
+)
+
+// IdentityError 加载身份材料失败时返回的错误
+// Kind为上面定义的错误类型之一，Err为底层原因（可能为nil）
+type IdentityError struct {
+	Kind error
+	Path string
+	Err  error
+}
+
+func (e *IdentityError) Error() string {
+	message := e.Kind.Error()
+	if e.Path != "" {
+		message += ": " + e.Path
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *IdentityError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Credentials 客户端身份及其签名函数
+// 使用完毕后调用Close释放签名后端持有的资源
+type Credentials struct {
+	Identity    *identity.X509Identity
+	Sign        identity.Sign
+	Certificate *x509.Certificate
+	closeSign   SignClose
+}
+
+// Close 释放签名后端（HSM会话、远程签名连接）
+func (c *Credentials) Close() error {
+	if c.closeSign == nil {
+		return nil
+	}
+	return c.closeSign()
+}
+
+// LoadCredentials 加载所选用户的身份和签名函数，并在签署第一笔交易之前完成校验：
+//  1. 证书和私钥文件存在且为合法的PEM
+//  2. 私钥类型受支持（ECDSA或Ed25519）
+//  3. 签名后端生成的签名能用证书中的公钥验证，即私钥与证书匹配
+//  4. 如果组织MSP目录下存在cacerts，证书必须由其中的CA签发
+func LoadCredentials(target *Target) (*Credentials, error) {
+	certificate, err := loadCertificate(target.User.Cert)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyMSP(certificate, target.User.Cert, target.MSPDir); err != nil {
+		return nil, err
+	}
+
+	id, err := identity.NewX509Identity(target.MSPID, certificate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	sign, closeSign, err := NewSign(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credentials{Identity: id, Sign: sign, Certificate: certificate, closeSign: closeSign}, nil
+}
+
+func loadCertificate(path string) (*x509.Certificate, error) {
+	certificatePEM, err := readPEMFile(path, ErrMissingCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, &IdentityError{Kind: ErrBadPEM, Path: path, Err: err}
+	}
+	return certificate, nil
+}
+
+func loadPrivateKey(path string) (crypto.PrivateKey, error) {
+	privateKeyPEM, err := readPEMFile(path, ErrMissingPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := identity.PrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, &IdentityError{Kind: ErrBadPEM, Path: path, Err: err}
+	}
+	return checkKeyType(path, privateKey)
+}
+
+// readPEMFile 读取文件并确认其中至少包含一个PEM块
+func readPEMFile(path string, missing error) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, &IdentityError{Kind: missing, Path: path}
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if block, _ := pem.Decode(data); block == nil {
+		return nil, &IdentityError{Kind: ErrBadPEM, Path: path}
+	}
+	return data, nil
+}
+
+// verifyMSP 使用组织MSP目录中的cacerts和intermediatecerts校验证书链，certPath为证书文件路径，用于错误信息
+// MSP目录或cacerts不存在时跳过校验
+func verifyMSP(certificate *x509.Certificate, certPath, mspDir string) error {
+	if mspDir == "" {
+		return nil
+	}
+
+	roots, err := loadCertPool(filepath.Join(mspDir, "cacerts"))
+	if err != nil || roots == nil {
+		return err
+	}
+	intermediates, err := loadCertPool(filepath.Join(mspDir, "intermediatecerts"))
+	if err != nil {
+		return err
+	}
+
+	_, err = certificate.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err == nil {
+		return nil
+	}
+
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) && invalid.Reason == x509.Expired {
+		return &IdentityError{Kind: ErrCertificateExpired, Path: certPath, Err: err}
+	}
+	return &IdentityError{Kind: ErrMSPMismatch, Path: mspDir, Err: err}
+}
+
+// loadCertPool 加载目录中的所有PEM证书，目录不存在或为空时返回nil
+func loadCertPool(dir string) (*x509.CertPool, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var pool *x509.CertPool
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		certificate, err := loadCertificate(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AddCert(certificate)
+	}
+	return pool, nil
+}
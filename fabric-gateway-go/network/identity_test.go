@@ -0,0 +1,162 @@
+package network
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key := newECDSAKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{certificate: certificate, key: key}
+}
+
+func (ca *testCA) issue(t *testing.T, publicKey crypto.PublicKey) []byte {
+	return ca.issueValidity(t, publicKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+}
+
+func (ca *testCA) issueValidity(t *testing.T, publicKey crypto.PublicKey, notBefore, notAfter time.Time) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "User1"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, publicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newECDSAKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func keyPEM(t *testing.T, key crypto.PrivateKey) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestTarget writes an MSP directory trusting ca and a user certificate/key pair.
+func newTestTarget(t *testing.T, ca *testCA, certificatePEM, privateKeyPEM []byte) *Target {
+	dir := t.TempDir()
+	target := &Target{
+		MSPID:  "Org1MSP",
+		MSPDir: filepath.Join(dir, "msp"),
+		User: User{
+			Cert: filepath.Join(dir, "signcerts", "cert.pem"),
+			Key:  filepath.Join(dir, "keystore", "priv_sk"),
+		},
+	}
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.certificate.Raw})
+	writeFile(t, filepath.Join(target.MSPDir, "cacerts", "ca.pem"), caPEM)
+	if certificatePEM != nil {
+		writeFile(t, target.User.Cert, certificatePEM)
+	}
+	if privateKeyPEM != nil {
+		writeFile(t, target.User.Key, privateKeyPEM)
+	}
+	return target
+}
+
+func Test_LoadCredentials(t *testing.T) {
+	ca := newTestCA(t, "ca.org1.example.com")
+	otherCA := newTestCA(t, "ca.org2.example.com")
+	key := newECDSAKey(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, testCase := range map[string]struct {
+		certificatePEM []byte
+		privateKeyPEM  []byte
+		expected       error
+	}{
+		"valid":            {ca.issue(t, &key.PublicKey), keyPEM(t, key), nil},
+		"missing cert":     {nil, keyPEM(t, key), ErrMissingCertificate},
+		"missing key":      {ca.issue(t, &key.PublicKey), nil, ErrMissingPrivateKey},
+		"bad cert PEM":     {[]byte("not a certificate"), keyPEM(t, key), ErrBadPEM},
+		"unsupported key":  {ca.issue(t, &rsaKey.PublicKey), keyPEM(t, rsaKey), ErrUnsupportedKeyType},
+		"key mismatch":     {ca.issue(t, &key.PublicKey), keyPEM(t, newECDSAKey(t)), ErrKeyMismatch},
+		"MSP mismatch":     {otherCA.issue(t, &key.PublicKey), keyPEM(t, key), ErrMSPMismatch},
+		"expired cert":     {ca.issueValidity(t, &key.PublicKey, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)), keyPEM(t, key), ErrCertificateExpired},
+		"bad key contents": {ca.issue(t, &key.PublicKey), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("x")}), ErrBadPEM},
+	} {
+		t.Run(name, func(t *testing.T) {
+			target := newTestTarget(t, ca, testCase.certificatePEM, testCase.privateKeyPEM)
+			credentials, err := LoadCredentials(target)
+			if testCase.expected == nil {
+				if err != nil {
+					t.Fatal("unexpected error:", err)
+				}
+				if _, err := credentials.Sign(make([]byte, 32)); err != nil {
+					t.Error("unexpected sign error:", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, testCase.expected) {
+				t.Fatalf("expected %v, got %v", testCase.expected, err)
+			}
+			var identityErr *IdentityError
+			if !errors.As(err, &identityErr) {
+				t.Fatalf("expected *IdentityError, got %T", err)
+			}
+			if testCase.expected == ErrCertificateExpired && identityErr.Path != target.User.Cert {
+				t.Errorf("expected path %s, got %s", target.User.Cert, identityErr.Path)
+			}
+		})
+	}
+}
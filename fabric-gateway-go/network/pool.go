@@ -0,0 +1,282 @@
+package network // 连接池 - 多个网关节点之间的健康检查、轮询和故障转移
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// ErrNoHealthyPeer 连接池中没有可用的网关节点
+var ErrNoHealthyPeer = errors.New("no healthy gateway peer")
+
+// 默认健康检查参数
+const (
+	defaultHealthCheckInterval = 5 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// ConnectionPool 管理到多个网关节点（可跨组织）的gRPC连接，实现grpc.ClientConnInterface，
+// 可直接传给client.WithClientConnection，调用方无需感知节点切换
+//
+// Evaluate请求在健康节点之间轮询；Endorse、Submit、CommitStatus和事件流
+// 发往配置顺序中第一个健康的节点，首选节点恢复后自动切回。
+// 调用返回Unavailable时，该节点被标记为不健康，请求透明地转发到下一个节点。
+type ConnectionPool struct {
+	members []*poolMember
+	next    atomic.Uint64 // Evaluate轮询计数
+
+	interval      time.Duration
+	timeout       time.Duration
+	healthService *string
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+// poolMember 连接池中的一个节点
+type poolMember struct {
+	peer    GatewayPeer
+	conn    *grpc.ClientConn
+	healthy atomic.Bool
+}
+
+// PeerStatus 节点的当前健康状态
+type PeerStatus struct {
+	Org      string
+	Name     string
+	Endpoint string
+	State    connectivity.State
+	Healthy  bool
+}
+
+// PoolOption 连接池配置项
+type PoolOption func(*ConnectionPool)
+
+// WithHealthCheckInterval 设置后台健康检查的间隔，默认5秒；0表示不做后台检查
+func WithHealthCheckInterval(interval time.Duration) PoolOption {
+	return func(p *ConnectionPool) {
+		p.interval = interval
+	}
+}
+
+// WithHealthCheckTimeout 设置单次gRPC健康检查的超时，默认2秒
+func WithHealthCheckTimeout(timeout time.Duration) PoolOption {
+	return func(p *ConnectionPool) {
+		p.timeout = timeout
+	}
+}
+
+// WithHealthService 使用gRPC健康检查协议（grpc.health.v1）检查节点，service为服务名，空字符串表示整个服务器
+// 默认只检查连接状态，因为Fabric节点默认不注册健康检查服务；节点返回Unimplemented时同样回退到连接状态
+func WithHealthService(service string) PoolOption {
+	return func(p *ConnectionPool) {
+		p.healthService = &service
+	}
+}
+
+// NewConnectionPool 为每个网关节点创建gRPC连接并启动后台健康检查
+// peers的顺序即节点的优先顺序，通常来自Profile.GatewayPeers
+func NewConnectionPool(peers []GatewayPeer, options ...PoolOption) (*ConnectionPool, error) {
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("connection pool requires at least one peer")
+	}
+
+	pool := &ConnectionPool{
+		interval: defaultHealthCheckInterval,
+		timeout:  defaultHealthCheckTimeout,
+		stop:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(pool)
+	}
+
+	for _, peer := range peers {
+		conn, err := NewPeerConnection(peer.Peer)
+		if err != nil {
+			pool.closeConnections()
+			return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", peer.Name, err)
+		}
+		member := &poolMember{peer: peer, conn: conn}
+		// 新连接处于Idle状态，在第一次调用或健康检查时才真正建立
+		member.healthy.Store(true)
+		conn.Connect()
+		pool.members = append(pool.members, member)
+	}
+
+	if pool.interval > 0 {
+		pool.stopped.Add(1)
+		go pool.healthCheckLoop()
+	}
+	return pool, nil
+}
+
+// Invoke 实现grpc.ClientConnInterface，按请求类型选择节点，遇到Unavailable时转移到下一个节点
+func (p *ConnectionPool) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	var lastErr error
+	for _, member := range p.candidates(method) {
+		err := member.conn.Invoke(ctx, method, args, reply, opts...)
+		if err == nil || !p.failover(ctx, member, err) {
+			return err
+		}
+		lastErr = err
+	}
+	return p.exhausted(lastErr)
+}
+
+// NewStream 实现grpc.ClientConnInterface；只有在建立流时失败才会转移，已建立的流中断由调用方重新订阅
+func (p *ConnectionPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	var lastErr error
+	for _, member := range p.candidates(method) {
+		stream, err := member.conn.NewStream(ctx, desc, method, opts...)
+		if err == nil || !p.failover(ctx, member, err) {
+			return stream, err
+		}
+		lastErr = err
+	}
+	return nil, p.exhausted(lastErr)
+}
+
+// candidates 返回本次调用依次尝试的节点：健康节点在前，不健康节点作为最后手段
+// Evaluate从轮询位置开始，其他请求按配置顺序
+func (p *ConnectionPool) candidates(method string) []*poolMember {
+	start := 0
+	if method == gateway.Gateway_Evaluate_FullMethodName {
+		start = int(p.next.Add(1)-1) % len(p.members)
+	}
+
+	healthy := make([]*poolMember, 0, len(p.members))
+	var unhealthy []*poolMember
+	for i := range p.members {
+		member := p.members[(start+i)%len(p.members)]
+		if member.healthy.Load() {
+			healthy = append(healthy, member)
+		} else {
+			unhealthy = append(unhealthy, member)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// failover 判断失败的调用是否应该转移到下一个节点，并把失败的节点标记为不健康
+// 只有Unavailable（节点不可达或正在重启）才转移；链码错误、超时和取消直接返回给调用方
+func (p *ConnectionPool) failover(ctx context.Context, member *poolMember, err error) bool {
+	if ctx.Err() != nil || status.Code(err) != codes.Unavailable {
+		return false
+	}
+	member.healthy.Store(false)
+	// 触发重连，后台健康检查在连接恢复后重新标记为健康
+	member.conn.Connect()
+	return true
+}
+
+func (p *ConnectionPool) exhausted(lastErr error) error {
+	return status.Errorf(codes.Unavailable, "%v: %v", ErrNoHealthyPeer, lastErr)
+}
+
+// Peers 返回各节点的健康状态，顺序与创建时相同
+func (p *ConnectionPool) Peers() []PeerStatus {
+	result := make([]PeerStatus, len(p.members))
+	for i, member := range p.members {
+		result[i] = PeerStatus{
+			Org:      member.peer.Org,
+			Name:     member.peer.Name,
+			Endpoint: member.peer.Peer.Endpoint,
+			State:    member.conn.GetState(),
+			Healthy:  member.healthy.Load(),
+		}
+	}
+	return result
+}
+
+// CheckHealth 立即检查所有节点，返回健康节点的数量
+func (p *ConnectionPool) CheckHealth(ctx context.Context) int {
+	var wg sync.WaitGroup
+	for _, member := range p.members {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			member.healthy.Store(p.check(ctx, member))
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	for _, member := range p.members {
+		if member.healthy.Load() {
+			count++
+		}
+	}
+	return count
+}
+
+// check 根据连接状态（以及可选的gRPC健康检查）判断节点是否健康
+func (p *ConnectionPool) check(ctx context.Context, member *poolMember) bool {
+	switch member.conn.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		member.conn.Connect()
+		return false
+	case connectivity.Idle:
+		// 空闲连接在下一次调用时建立，先视为健康
+		member.conn.Connect()
+		return true
+	case connectivity.Connecting:
+		return member.healthy.Load()
+	}
+
+	if p.healthService == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	response, err := healthpb.NewHealthClient(member.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: *p.healthService})
+	if status.Code(err) == codes.Unimplemented {
+		return true
+	}
+	return err == nil && response.GetStatus() == healthpb.HealthCheckResponse_SERVING
+}
+
+func (p *ConnectionPool) healthCheckLoop() {
+	defer p.stopped.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.CheckHealth(context.Background())
+		}
+	}
+}
+
+// Close 停止健康检查并关闭所有连接
+func (p *ConnectionPool) Close() error {
+	p.once.Do(func() {
+		close(p.stop)
+		p.stopped.Wait()
+	})
+	return p.closeConnections()
+}
+
+func (p *ConnectionPool) closeConnections() error {
+	var errs []error
+	for _, member := range p.members {
+		if err := member.conn.Close(); err != nil && status.Code(err) != codes.Canceled {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
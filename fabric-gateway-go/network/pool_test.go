@@ -0,0 +1,124 @@
+package network
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// testGateway answers Evaluate and Endorse with its own name
+type testGateway struct {
+	gateway.UnimplementedGatewayServer
+	name string
+}
+
+func (g *testGateway) Evaluate(context.Context, *gateway.EvaluateRequest) (*gateway.EvaluateResponse, error) {
+	return &gateway.EvaluateResponse{Result: &peer.Response{Payload: []byte(g.name)}}, nil
+}
+
+func (g *testGateway) Endorse(context.Context, *gateway.EndorseRequest) (*gateway.EndorseResponse, error) {
+	return &gateway.EndorseResponse{PreparedTransaction: &common.Envelope{Payload: []byte(g.name)}}, nil
+}
+
+// newTestPool starts a gateway server per name and returns a pool without background health checks
+func newTestPool(t *testing.T, names ...string) (*ConnectionPool, map[string]*grpc.Server) {
+	t.Helper()
+	pool := &ConnectionPool{stop: make(chan struct{})}
+	servers := make(map[string]*grpc.Server)
+	for _, name := range names {
+		socket := filepath.Join(t.TempDir(), name+".sock")
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := grpc.NewServer()
+		gateway.RegisterGatewayServer(server, &testGateway{name: name})
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+		servers[name] = server
+
+		conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		member := &poolMember{peer: GatewayPeer{Name: name}, conn: conn}
+		member.healthy.Store(true)
+		pool.members = append(pool.members, member)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool, servers
+}
+
+func evaluate(t *testing.T, client gateway.GatewayClient) string {
+	t.Helper()
+	response, err := client.Evaluate(context.Background(), &gateway.EvaluateRequest{})
+	if err != nil {
+		t.Fatal("unexpected evaluate error:", err)
+	}
+	return string(response.GetResult().GetPayload())
+}
+
+func endorse(t *testing.T, client gateway.GatewayClient) string {
+	t.Helper()
+	response, err := client.Endorse(context.Background(), &gateway.EndorseRequest{})
+	if err != nil {
+		t.Fatal("unexpected endorse error:", err)
+	}
+	return string(response.GetPreparedTransaction().GetPayload())
+}
+
+func Test_ConnectionPool_RoundRobinEvaluate(t *testing.T) {
+	pool, _ := newTestPool(t, "peer0", "peer1")
+	client := gateway.NewGatewayClient(pool)
+
+	seen := map[string]int{}
+	for range 4 {
+		seen[evaluate(t, client)]++
+	}
+	if seen["peer0"] != 2 || seen["peer1"] != 2 {
+		t.Errorf("expected evaluates spread evenly across peers, got %v", seen)
+	}
+
+	for range 3 {
+		if got := endorse(t, client); got != "peer0" {
+			t.Errorf("expected endorse on preferred peer peer0, got %s", got)
+		}
+	}
+}
+
+func Test_ConnectionPool_Failover(t *testing.T) {
+	pool, servers := newTestPool(t, "peer0", "peer1")
+	client := gateway.NewGatewayClient(pool)
+
+	if got := endorse(t, client); got != "peer0" {
+		t.Fatalf("expected endorse on peer0, got %s", got)
+	}
+
+	servers["peer0"].Stop()
+
+	if got := endorse(t, client); got != "peer1" {
+		t.Errorf("expected endorse to fail over to peer1, got %s", got)
+	}
+	for range 3 {
+		if got := evaluate(t, client); got != "peer1" {
+			t.Errorf("expected evaluate on peer1 while peer0 is down, got %s", got)
+		}
+	}
+
+	status := pool.Peers()
+	if status[0].Healthy || !status[1].Healthy {
+		t.Errorf("expected only peer1 healthy, got %+v", status)
+	}
+
+	servers["peer1"].Stop()
+	if _, err := client.Evaluate(context.Background(), &gateway.EvaluateRequest{}); err == nil {
+		t.Error("expected error with no peers available")
+	}
+}
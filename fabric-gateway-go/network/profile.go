@@ -0,0 +1,318 @@
+package network // 连接配置 - 从YAML/JSON连接配置文件或环境变量加载组织、节点和用户信息
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3" // YAML解析库，YAML是JSON的超集，因此同样可以解析JSON格式的配置文件
+)
+
+// 环境变量名称
+const (
+	EnvProfile      = "FABRIC_PROFILE"         // 连接配置文件路径（YAML或JSON）
+	EnvOrg          = "FABRIC_ORG"             // 选择的组织名称
+	EnvPeer         = "FABRIC_PEER"            // 选择的节点名称
+	EnvUser         = "FABRIC_USER"            // 选择的用户名称
+	EnvMSPID        = "FABRIC_MSP_ID"          // 未使用配置文件时的MSP ID
+	EnvCryptoPath   = "FABRIC_CRYPTO_PATH"     // 未使用配置文件时的加密材料根目录
+	EnvCertPath     = "FABRIC_CERT_PATH"       // 未使用配置文件时的用户证书路径
+	EnvKeyPath      = "FABRIC_KEY_PATH"        // 未使用配置文件时的用户私钥路径
+	EnvTLSCertPath  = "FABRIC_TLS_CERT_PATH"   // 未使用配置文件时的TLS CA证书路径
+	EnvPeerEndpoint = "FABRIC_PEER_ENDPOINT"   // 未使用配置文件时的网关节点地址
+	EnvPeerHost     = "FABRIC_PEER_HOST_ALIAS" // 未使用配置文件时的TLS SNI主机名
+)
+
+// 默认值与test-network中Org1的User1保持一致
+const (
+	defaultOrg        = "Org1"
+	defaultMSPID      = "Org1MSP"
+	defaultPeer       = "peer0.org1.example.com"
+	defaultUser       = "User1"
+	defaultCryptoPath = "../test-network/organizations/peerOrganizations/org1.example.com"
+	defaultEndpoint   = "dns:///localhost:7051"
+)
+
+// Profile 连接配置，描述一个或多个组织及其节点和用户
+type Profile struct {
+	Organizations map[string]*Organization `yaml:"organizations" json:"organizations"`
+}
+
+// Organization 组织配置
+// CryptoPath为该组织加密材料的根目录，节点和用户中的相对路径都基于它解析
+// MSPDir为组织MSP目录，其中的cacerts用于校验用户证书是否属于该组织，默认为msp
+type Organization struct {
+	MSPID      string           `yaml:"mspid" json:"mspid"`
+	CryptoPath string           `yaml:"cryptoPath" json:"cryptoPath"`
+	MSPDir     string           `yaml:"mspDir" json:"mspDir"`
+	Peers      map[string]*Peer `yaml:"peers" json:"peers"`
+	Users      map[string]*User `yaml:"users" json:"users"`
+}
+
+// Peer 网关节点配置
+type Peer struct {
+	Endpoint     string `yaml:"endpoint" json:"endpoint"`         // gRPC地址，例如dns:///localhost:7051
+	HostOverride string `yaml:"hostOverride" json:"hostOverride"` // TLS SNI主机名，为空时使用节点名称
+	TLSCACert    string `yaml:"tlsCACert" json:"tlsCACert"`       // TLS CA证书路径
+}
+
+// User 用户身份配置
+// Signer为空时使用Key指向的明文PEM私钥签名
+type User struct {
+	Cert   string        `yaml:"cert" json:"cert"`     // 用户X.509证书路径
+	Key    string        `yaml:"key" json:"key"`       // 用户私钥路径（pem和encrypted-pem后端）
+	Signer *SignerConfig `yaml:"signer" json:"signer"` // 签名后端配置
+}
+
+// Target 从Profile中选出的一组组织、节点和用户，所有路径均已解析
+type Target struct {
+	Org      string
+	MSPID    string
+	MSPDir   string
+	PeerName string
+	Peer     Peer
+	UserName string
+	User     User
+}
+
+// GatewayPeer 连接池中的一个网关节点，路径均已解析
+type GatewayPeer struct {
+	Org  string
+	Name string
+	Peer Peer
+}
+
+// LoadProfile 从YAML或JSON文件加载连接配置
+// 配置中的相对CryptoPath基于配置文件所在目录解析
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read connection profile: %w", err)
+	}
+
+	profile := &Profile{}
+	if err := yaml.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("failed to parse connection profile %s: %w", path, err)
+	}
+	if len(profile.Organizations) == 0 {
+		return nil, fmt.Errorf("connection profile %s defines no organizations", path)
+	}
+
+	baseDir := filepath.Dir(path)
+	for orgName, org := range profile.Organizations {
+		if org == nil {
+			return nil, fmt.Errorf("connection profile %s: organization %s is empty", path, orgName)
+		}
+		if org.CryptoPath != "" && !filepath.IsAbs(org.CryptoPath) {
+			org.CryptoPath = filepath.Join(baseDir, org.CryptoPath)
+		}
+	}
+
+	return profile, nil
+}
+
+// ProfileFromEnv 根据环境变量构建只包含一个组织、一个节点和一个用户的连接配置
+// 未设置的变量使用test-network中Org1的默认值
+func ProfileFromEnv() *Profile {
+	orgName := envOrDefault(EnvOrg, defaultOrg)
+	peerName := envOrDefault(EnvPeer, defaultPeer)
+	userName := envOrDefault(EnvUser, defaultUser)
+	cryptoPath := envOrDefault(EnvCryptoPath, defaultCryptoPath)
+	userDir := "users/" + userName + "@" + filepath.Base(cryptoPath)
+
+	return &Profile{
+		Organizations: map[string]*Organization{
+			orgName: {
+				MSPID:      envOrDefault(EnvMSPID, defaultMSPID),
+				CryptoPath: cryptoPath,
+				Peers: map[string]*Peer{
+					peerName: {
+						Endpoint:     envOrDefault(EnvPeerEndpoint, defaultEndpoint),
+						HostOverride: envOrDefault(EnvPeerHost, peerName),
+						TLSCACert:    envOrDefault(EnvTLSCertPath, "tlsca/tlsca."+filepath.Base(cryptoPath)+"-cert.pem"),
+					},
+				},
+				Users: map[string]*User{
+					userName: {
+						Cert: envOrDefault(EnvCertPath, userDir+"/msp/signcerts/"+userName+"@"+filepath.Base(cryptoPath)+"-cert.pem"),
+						Key:  envOrDefault(EnvKeyPath, userDir+"/msp/keystore/priv_sk"),
+					},
+				},
+			},
+		},
+	}
+}
+
+// LoadProfileFromEnv 如果设置了FABRIC_PROFILE则加载该配置文件，否则使用环境变量构建配置
+func LoadProfileFromEnv() (*Profile, error) {
+	if path := os.Getenv(EnvProfile); path != "" {
+		return LoadProfile(path)
+	}
+	return ProfileFromEnv(), nil
+}
+
+// TargetFromEnv 加载连接配置，并按FABRIC_ORG、FABRIC_PEER和FABRIC_USER选择连接目标
+func TargetFromEnv() (*Target, error) {
+	profile, err := LoadProfileFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return profile.Select(os.Getenv(EnvOrg), os.Getenv(EnvPeer), os.Getenv(EnvUser))
+}
+
+// Select 按名称选择组织、节点和用户
+// 名称为空时，选择按名称排序后的第一个条目
+func (p *Profile) Select(orgName, peerName, userName string) (*Target, error) {
+	orgName, err := pick("organization", orgName, keys(p.Organizations))
+	if err != nil {
+		return nil, err
+	}
+	org, err := p.organization(orgName)
+	if err != nil {
+		return nil, err
+	}
+	if org.MSPID == "" {
+		return nil, fmt.Errorf("organization %s has no mspid", orgName)
+	}
+
+	peerName, err = pick("peer", peerName, keys(org.Peers))
+	if err != nil {
+		return nil, fmt.Errorf("organization %s: %w", orgName, err)
+	}
+	peer, err := org.peer(peerName)
+	if err != nil {
+		return nil, err
+	}
+
+	userName, err = pick("user", userName, keys(org.Users))
+	if err != nil {
+		return nil, fmt.Errorf("organization %s: %w", orgName, err)
+	}
+	if org.Users[userName] == nil {
+		return nil, fmt.Errorf("organization %s: user %s is empty", orgName, userName)
+	}
+	user := *org.Users[userName]
+	user.Cert = org.resolve(user.Cert)
+	user.Key = org.resolve(user.Key)
+
+	mspDir := org.MSPDir
+	if mspDir == "" {
+		mspDir = "msp"
+	}
+
+	return &Target{
+		Org:      orgName,
+		MSPID:    org.MSPID,
+		MSPDir:   org.resolve(mspDir),
+		PeerName: peerName,
+		Peer:     peer,
+		UserName: userName,
+		User:     user,
+	}, nil
+}
+
+// GatewayPeers 返回配置中所有组织的网关节点，供连接池使用
+// target所选的节点排在最前，其次是同一组织的其他节点，最后是其他组织的节点，各部分按名称排序
+func (p *Profile) GatewayPeers(target *Target) ([]GatewayPeer, error) {
+	var preferred, sameOrg, others []GatewayPeer
+	for _, orgName := range keys(p.Organizations) {
+		org, err := p.organization(orgName)
+		if err != nil {
+			return nil, err
+		}
+		for _, peerName := range keys(org.Peers) {
+			peer, err := org.peer(peerName)
+			if err != nil {
+				return nil, fmt.Errorf("organization %s: %w", orgName, err)
+			}
+			gatewayPeer := GatewayPeer{Org: orgName, Name: peerName, Peer: peer}
+			switch {
+			case target != nil && orgName == target.Org && peerName == target.PeerName:
+				preferred = append(preferred, gatewayPeer)
+			case target != nil && orgName == target.Org:
+				sameOrg = append(sameOrg, gatewayPeer)
+			default:
+				others = append(others, gatewayPeer)
+			}
+		}
+	}
+
+	peers := append(append(preferred, sameOrg...), others...)
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("no peer configured")
+	}
+	return peers, nil
+}
+
+// organization 返回指定名称的组织配置，组织不存在或条目为空时返回错误
+func (p *Profile) organization(name string) (*Organization, error) {
+	org, ok := p.Organizations[name]
+	if !ok {
+		return nil, fmt.Errorf("organization %s not found in connection profile", name)
+	}
+	if org == nil {
+		return nil, fmt.Errorf("organization %s is empty", name)
+	}
+	return org, nil
+}
+
+// peer 返回指定名称的节点配置，TLS证书路径已解析，HostOverride默认为节点名称
+func (org *Organization) peer(name string) (Peer, error) {
+	entry, ok := org.Peers[name]
+	if !ok {
+		return Peer{}, fmt.Errorf("peer %s not found in connection profile", name)
+	}
+	if entry == nil {
+		return Peer{}, fmt.Errorf("peer %s is empty", name)
+	}
+	peer := *entry
+	if peer.Endpoint == "" {
+		return Peer{}, fmt.Errorf("peer %s has no endpoint", name)
+	}
+	if peer.HostOverride == "" {
+		peer.HostOverride = name
+	}
+	peer.TLSCACert = org.resolve(peer.TLSCACert)
+	return peer, nil
+}
+
+// resolve 将相对路径解析为基于组织CryptoPath的路径
+func (org *Organization) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || org.CryptoPath == "" {
+		return path
+	}
+	return filepath.Join(org.CryptoPath, path)
+}
+
+func pick(kind, name string, names []string) (string, error) {
+	if len(names) == 0 {
+		return "", fmt.Errorf("no %s configured", kind)
+	}
+	if name == "" {
+		return names[0], nil
+	}
+	for _, candidate := range names {
+		if candidate == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%s %s not found in connection profile", kind, name)
+}
+
+func keys[V any](m map[string]V) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func envOrDefault(key, defaultValue string) string {
+	result := os.Getenv(key)
+	if result == "" {
+		return defaultValue
+	}
+	return result
+}
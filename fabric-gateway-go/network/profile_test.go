@@ -0,0 +1,140 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testProfile = `{
+  "organizations": {
+    "Org1": {
+      "mspid": "Org1MSP",
+      "cryptoPath": "crypto/org1",
+      "peers": {"peer0.org1.example.com": {"endpoint": "dns:///localhost:7051", "tlsCACert": "tlsca/ca.pem"}},
+      "users": {"User1": {"cert": "users/User1/cert.pem", "key": "/abs/key.pem"}}
+    },
+    "Org2": {
+      "mspid": "Org2MSP",
+      "peers": {"peer0.org2.example.com": {"endpoint": "dns:///localhost:9051", "hostOverride": "peer0.org2"}},
+      "users": {"Admin": {"cert": "a.pem", "key": "b.pem"}, "User1": {"cert": "c.pem", "key": "d.pem"}}
+    }
+  }
+}`
+
+func Test_LoadProfileAndSelect(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.json")
+	if err := os.WriteFile(path, []byte(testProfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := LoadProfile(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	target, err := profile.Select("Org1", "", "")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if target.MSPID != "Org1MSP" || target.PeerName != "peer0.org1.example.com" || target.UserName != "User1" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	if expected := filepath.Join(dir, "crypto/org1/tlsca/ca.pem"); target.Peer.TLSCACert != expected {
+		t.Errorf("expected TLS CA cert %s, got %s", expected, target.Peer.TLSCACert)
+	}
+	if target.Peer.HostOverride != "peer0.org1.example.com" {
+		t.Errorf("expected host override to default to peer name, got %s", target.Peer.HostOverride)
+	}
+	if target.User.Key != "/abs/key.pem" {
+		t.Errorf("expected absolute key path to be kept, got %s", target.User.Key)
+	}
+
+	target, err = profile.Select("Org2", "", "User1")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if target.Peer.HostOverride != "peer0.org2" || target.User.Cert != "c.pem" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+
+	if _, err := profile.Select("Org3", "", ""); err == nil {
+		t.Error("expected error for unknown organization")
+	}
+	if _, err := profile.Select("Org2", "", "User9"); err == nil {
+		t.Error("expected error for unknown user")
+	}
+}
+
+// Entries without a body decode to nil
+const malformedProfile = `
+organizations:
+  Org1:
+    mspid: Org1MSP
+    peers:
+      peer0.org1.example.com:
+    users:
+      User1:
+  Org2:
+`
+
+func Test_MalformedProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.yaml")
+	if err := os.WriteFile(path, []byte(malformedProfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfile(path); err == nil || !strings.Contains(err.Error(), "organization Org2 is empty") {
+		t.Errorf("expected error for empty organization, got %v", err)
+	}
+
+	profile := &Profile{Organizations: map[string]*Organization{
+		"Org1": {
+			MSPID: "Org1MSP",
+			Peers: map[string]*Peer{"peer0.org1.example.com": nil},
+			Users: map[string]*User{"User1": nil},
+		},
+		"Org2": nil,
+	}}
+	for _, test := range []struct {
+		org, peer, expected string
+	}{
+		{"Org1", "", "peer peer0.org1.example.com is empty"},
+		{"Org2", "", "organization Org2 is empty"},
+	} {
+		if _, err := profile.Select(test.org, test.peer, ""); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("expected error %q selecting %s, got %v", test.expected, test.org, err)
+		}
+	}
+	if _, err := profile.GatewayPeers(nil); err == nil {
+		t.Error("expected error for empty entries")
+	}
+	if _, err := profile.Organizations["Org1"].peer("peer9"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected error for unknown peer, got %v", err)
+	}
+
+	profile.Organizations["Org1"].Peers["peer0.org1.example.com"] = &Peer{Endpoint: "dns:///localhost:7051"}
+	if _, err := profile.Select("Org1", "", ""); err == nil || !strings.Contains(err.Error(), "user User1 is empty") {
+		t.Errorf("expected error for empty user, got %v", err)
+	}
+}
+
+func Test_ProfileFromEnv(t *testing.T) {
+	t.Setenv(EnvOrg, "Org2")
+	t.Setenv(EnvMSPID, "Org2MSP")
+	t.Setenv(EnvPeer, "peer0.org2.example.com")
+	t.Setenv(EnvPeerEndpoint, "dns:///localhost:9051")
+	t.Setenv(EnvCryptoPath, "/crypto/org2.example.com")
+
+	target, err := TargetFromEnv()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if target.Org != "Org2" || target.MSPID != "Org2MSP" || target.Peer.Endpoint != "dns:///localhost:9051" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	if expected := "/crypto/org2.example.com/users/User1@org2.example.com/msp/keystore/priv_sk"; target.User.Key != expected {
+		t.Errorf("expected key path %s, got %s", expected, target.User.Key)
+	}
+}
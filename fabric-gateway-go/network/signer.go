@@ -0,0 +1,207 @@
+package network // 签名后端 - 支持明文PEM、加密PEM、PKCS#11和远程签名进程
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/youmark/pkcs8" // 解析PKCS#8加密私钥（ENCRYPTED PRIVATE KEY）
+)
+
+// 签名后端类型
+const (
+	SignerPEM          = "pem"           // 明文PEM私钥（默认）
+	SignerEncryptedPEM = "encrypted-pem" // 使用口令加密的PKCS#8 PEM私钥
+	SignerPKCS11       = "pkcs11"        // PKCS#11硬件安全模块，例如SoftHSM
+	SignerRemote       = "remote"        // 通过本地gRPC/Unix socket访问的外部签名进程
+)
+
+// SignerConfig 签名后端配置，未配置时使用User.Key中的明文PEM私钥
+type SignerConfig struct {
+	Type string `yaml:"type" json:"type"`
+
+	// 加密PEM的口令来源：环境变量名称或文件路径
+	PassphraseEnv  string `yaml:"passphraseEnv" json:"passphraseEnv"`
+	PassphraseFile string `yaml:"passphraseFile" json:"passphraseFile"`
+
+	PKCS11 *PKCS11Config `yaml:"pkcs11" json:"pkcs11"`
+	Remote *RemoteConfig `yaml:"remote" json:"remote"`
+}
+
+// PKCS11Config PKCS#11令牌配置
+// Identifier为私钥的CKA_ID，为空时使用证书公钥的SKI（与Fabric BCCSP一致）
+type PKCS11Config struct {
+	Library    string `yaml:"library" json:"library"`
+	Label      string `yaml:"label" json:"label"`
+	PinEnv     string `yaml:"pinEnv" json:"pinEnv"`
+	Identifier string `yaml:"identifier" json:"identifier"`
+}
+
+// RemoteConfig 远程签名进程配置
+// Address为gRPC地址，例如unix:///var/run/fabric-signer.sock
+type RemoteConfig struct {
+	Address string `yaml:"address" json:"address"`
+}
+
+// SignClose 释放签名后端持有的资源（HSM会话、gRPC连接等）
+type SignClose = func() error
+
+// ErrSignerConfig 签名后端配置错误
+var ErrSignerConfig = errors.New("invalid signer configuration")
+
+func noClose() error { return nil }
+
+// newSigner 根据用户的签名后端配置创建签名函数
+func newSigner(user User) (identity.Sign, SignClose, error) {
+	config := user.Signer
+	if config == nil {
+		config = &SignerConfig{}
+	}
+
+	switch config.Type {
+	case "", SignerPEM:
+		sign, err := newPEMSign(user.Key, nil)
+		return sign, noClose, err
+	case SignerEncryptedPEM:
+		passphrase, err := readSecret(config.PassphraseEnv, config.PassphraseFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		sign, err := newPEMSign(user.Key, passphrase)
+		return sign, noClose, err
+	case SignerPKCS11:
+		if config.PKCS11 == nil {
+			return nil, nil, fmt.Errorf("%w: pkcs11 section missing", ErrSignerConfig)
+		}
+		return newPKCS11Sign(config.PKCS11, user.Cert)
+	case SignerRemote:
+		if config.Remote == nil || config.Remote.Address == "" {
+			return nil, nil, fmt.Errorf("%w: remote address missing", ErrSignerConfig)
+		}
+		return newRemoteSign(config.Remote.Address)
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown signer type %s", ErrSignerConfig, config.Type)
+	}
+}
+
+// newPEMSign 从PEM文件加载私钥；passphrase不为nil时按PKCS#8加密私钥解密
+func newPEMSign(path string, passphrase []byte) (identity.Sign, error) {
+	var (
+		privateKey crypto.PrivateKey
+		err        error
+	)
+	if passphrase == nil {
+		privateKey, err = loadPrivateKey(path)
+	} else {
+		privateKey, err = loadEncryptedPrivateKey(path, passphrase)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sign, err := identity.NewPrivateKeySign(privateKey)
+	if err != nil {
+		return nil, &IdentityError{Kind: ErrUnsupportedKeyType, Path: path, Err: err}
+	}
+	return sign, nil
+}
+
+func loadEncryptedPrivateKey(path string, passphrase []byte) (crypto.PrivateKey, error) {
+	data, err := readPEMFile(path, ErrMissingPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block.Type != "ENCRYPTED PRIVATE KEY" {
+		return nil, &IdentityError{Kind: ErrBadPEM, Path: path, Err: fmt.Errorf("expected ENCRYPTED PRIVATE KEY, got %s", block.Type)}
+	}
+
+	privateKey, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, passphrase)
+	if err != nil {
+		return nil, &IdentityError{Kind: ErrBadPEM, Path: path, Err: err}
+	}
+	return checkKeyType(path, privateKey)
+}
+
+func checkKeyType(path string, privateKey crypto.PrivateKey) (crypto.PrivateKey, error) {
+	switch privateKey.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return privateKey, nil
+	default:
+		return nil, &IdentityError{Kind: ErrUnsupportedKeyType, Path: path, Err: fmt.Errorf("%T", privateKey)}
+	}
+}
+
+// readSecret 从环境变量或文件读取口令/PIN，文件内容末尾的换行会被去掉
+func readSecret(envName, filePath string) ([]byte, error) {
+	if envName != "" {
+		if value, ok := os.LookupEnv(envName); ok {
+			return []byte(value), nil
+		}
+		return nil, fmt.Errorf("%w: environment variable %s not set", ErrSignerConfig, envName)
+	}
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSignerConfig, err)
+		}
+		return []byte(strings.TrimRight(string(data), "\r\n")), nil
+	}
+	return nil, fmt.Errorf("%w: no passphrase source configured", ErrSignerConfig)
+}
+
+// subjectKeyIdentifier 计算证书公钥的SKI：未压缩EC公钥点的SHA-256
+func subjectKeyIdentifier(certPath string) (string, error) {
+	certificate, err := loadCertificate(certPath)
+	if err != nil {
+		return "", err
+	}
+	publicKey, ok := certificate.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return "", &IdentityError{Kind: ErrUnsupportedKeyType, Path: certPath, Err: fmt.Errorf("%T", certificate.PublicKey)}
+	}
+	point, err := publicKey.ECDH()
+	if err != nil {
+		return "", err
+	}
+	ski := sha256.Sum256(point.Bytes())
+	return string(ski[:]), nil
+}
+
+// checkSignature 用签名后端签署一个随机摘要，并用证书公钥验证签名，certPath为证书文件路径，用于错误信息
+// 这样无论私钥位于本地文件、HSM还是远程进程，都能确认它与证书匹配
+func checkSignature(certificate *x509.Certificate, certPath string, sign identity.Sign) error {
+	digest := make([]byte, sha256.Size)
+	if _, err := rand.Read(digest); err != nil {
+		return err
+	}
+
+	signature, err := sign(digest)
+	if err != nil {
+		return fmt.Errorf("test signature failed: %w", err)
+	}
+
+	switch publicKey := certificate.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(publicKey, digest, signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(publicKey, digest, signature) {
+			return nil
+		}
+	default:
+		return &IdentityError{Kind: ErrUnsupportedKeyType, Path: certPath, Err: fmt.Errorf("certificate key %T", publicKey)}
+	}
+	return &IdentityError{Kind: ErrKeyMismatch, Path: certPath, Err: fmt.Errorf("signature does not verify against certificate %s", certificate.Subject.CommonName)}
+}
@@ -0,0 +1,14 @@
+//go:build !pkcs11
+
+package network
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// newPKCS11Sign 未使用 -tags pkcs11 构建时PKCS#11后端不可用
+func newPKCS11Sign(*PKCS11Config, string) (identity.Sign, SignClose, error) {
+	return nil, nil, fmt.Errorf("%w: PKCS#11 support requires building with -tags pkcs11", ErrSignerConfig)
+}
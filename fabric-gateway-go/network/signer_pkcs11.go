@@ -0,0 +1,65 @@
+//go:build pkcs11
+
+package network // PKCS#11签名 - 需要使用 -tags pkcs11 构建（依赖cgo）
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+)
+
+// 同一个PKCS#11库只能初始化一次，因此按库路径缓存工厂
+var (
+	hsmFactoriesLock sync.Mutex
+	hsmFactories     = map[string]*identity.HSMSignerFactory{}
+)
+
+func hsmFactory(library string) (*identity.HSMSignerFactory, error) {
+	hsmFactoriesLock.Lock()
+	defer hsmFactoriesLock.Unlock()
+
+	if factory, ok := hsmFactories[library]; ok {
+		return factory, nil
+	}
+	factory, err := identity.NewHSMSignerFactory(library)
+	if err != nil {
+		return nil, err
+	}
+	hsmFactories[library] = factory
+	return factory, nil
+}
+
+// newPKCS11Sign 使用PKCS#11令牌中的私钥创建签名函数，例如SoftHSM：
+//
+//	library: /usr/lib/softhsm/libsofthsm2.so
+//	label:   ForFabric
+//	pinEnv:  FABRIC_HSM_PIN
+func newPKCS11Sign(config *PKCS11Config, certPath string) (identity.Sign, SignClose, error) {
+	pin, err := readSecret(config.PinEnv, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identifier := config.Identifier
+	if identifier == "" {
+		if identifier, err = subjectKeyIdentifier(certPath); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	factory, err := hsmFactory(config.Library)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load PKCS#11 library %s: %w", config.Library, err)
+	}
+
+	sign, closeSign, err := factory.NewHSMSigner(identity.HSMSignerOptions{
+		Label:      config.Label,
+		Pin:        string(pin),
+		Identifier: identifier,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open PKCS#11 token %s: %w", config.Label, err)
+	}
+	return sign, closeSign, nil
+}
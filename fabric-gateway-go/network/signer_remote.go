@@ -0,0 +1,73 @@
+package network // 远程签名 - 通过本地gRPC/Unix socket调用外部签名进程
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// 远程签名服务使用protobuf的BytesValue作为请求（摘要）和响应（签名），无需额外的.proto文件
+const (
+	signerServiceName = "fabric.signer.v1.Signer"
+	signMethod        = "/" + signerServiceName + "/Sign"
+)
+
+// remoteSignTimeout 单次远程签名的超时时间
+const remoteSignTimeout = 10 * time.Second
+
+// newRemoteSign 连接到外部签名进程
+// 签名进程只通过本机Unix socket暴露，因此连接不使用TLS
+func newRemoteSign(address string) (identity.Sign, SignClose, error) {
+	connection, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to remote signer %s: %w", address, err)
+	}
+
+	sign := func(digest []byte) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteSignTimeout)
+		defer cancel()
+
+		signature := &wrapperspb.BytesValue{}
+		if err := connection.Invoke(ctx, signMethod, wrapperspb.Bytes(digest), signature); err != nil {
+			return nil, fmt.Errorf("remote signer %s: %w", address, err)
+		}
+		return signature.GetValue(), nil
+	}
+
+	return sign, connection.Close, nil
+}
+
+// RegisterSignerServer 在gRPC服务器上注册远程签名服务，供独立的签名进程使用
+// 例如在持有私钥（或HSM会话）的进程中：
+//
+//	server := grpc.NewServer()
+//	network.RegisterSignerServer(server, sign)
+//	listener, _ := net.Listen("unix", "/var/run/fabric-signer.sock")
+//	server.Serve(listener)
+func RegisterSignerServer(server grpc.ServiceRegistrar, sign identity.Sign) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: signerServiceName,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{
+				MethodName: "Sign",
+				Handler: func(_ any, ctx context.Context, decode func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+					digest := &wrapperspb.BytesValue{}
+					if err := decode(digest); err != nil {
+						return nil, err
+					}
+					signature, err := sign(digest.GetValue())
+					if err != nil {
+						return nil, err
+					}
+					return wrapperspb.Bytes(signature), nil
+				},
+			},
+		},
+	}, struct{}{})
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"encoding/pem"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/youmark/pkcs8"
+	"google.golang.org/grpc"
+)
+
+func Test_EncryptedPEMSigner(t *testing.T) {
+	ca := newTestCA(t, "ca.org1.example.com")
+	key := newECDSAKey(t)
+	der, err := pkcs8.MarshalPrivateKey(key, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedPEM := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})
+
+	target := newTestTarget(t, ca, ca.issue(t, &key.PublicKey), encryptedPEM)
+	target.User.Signer = &SignerConfig{Type: SignerEncryptedPEM, PassphraseEnv: "TEST_KEY_PASSPHRASE"}
+
+	t.Setenv("TEST_KEY_PASSPHRASE", "secret")
+	credentials, err := LoadCredentials(target)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer credentials.Close()
+
+	t.Setenv("TEST_KEY_PASSPHRASE", "wrong")
+	if _, err := LoadCredentials(target); !errors.Is(err, ErrBadPEM) {
+		t.Errorf("expected %v for wrong passphrase, got %v", ErrBadPEM, err)
+	}
+
+	target.User.Signer = nil
+	if _, err := LoadCredentials(target); !errors.Is(err, ErrBadPEM) {
+		t.Errorf("expected %v for encrypted key without passphrase, got %v", ErrBadPEM, err)
+	}
+}
+
+func Test_RemoteSigner(t *testing.T) {
+	ca := newTestCA(t, "ca.org1.example.com")
+	key := newECDSAKey(t)
+	sign, err := identity.NewPrivateKeySign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	RegisterSignerServer(server, sign)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	signer := &SignerConfig{Type: SignerRemote, Remote: &RemoteConfig{Address: "unix://" + socket}}
+
+	target := newTestTarget(t, ca, ca.issue(t, &key.PublicKey), nil)
+	target.User.Signer = signer
+	credentials, err := LoadCredentials(target)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := credentials.Close(); err != nil {
+		t.Error("unexpected close error:", err)
+	}
+
+	target = newTestTarget(t, ca, ca.issue(t, &newECDSAKey(t).PublicKey), nil)
+	target.User.Signer = signer
+	if _, err := LoadCredentials(target); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("expected %v, got %v", ErrKeyMismatch, err)
+	}
+	if _, _, err := NewSign(target); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("expected %v from NewSign, got %v", ErrKeyMismatch, err)
+	}
+}
+
+func Test_NewSignChecksKey(t *testing.T) {
+	ca := newTestCA(t, "ca.org1.example.com")
+	key := newECDSAKey(t)
+	der, err := pkcs8.MarshalPrivateKey(key, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryptedPEM := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: der})
+	t.Setenv("TEST_KEY_PASSPHRASE", "secret")
+
+	target := newTestTarget(t, ca, ca.issue(t, &key.PublicKey), encryptedPEM)
+	target.User.Signer = &SignerConfig{Type: SignerEncryptedPEM, PassphraseEnv: "TEST_KEY_PASSPHRASE"}
+	sign, closeSign, err := NewSign(target)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer closeSign()
+	if _, err := sign(make([]byte, 32)); err != nil {
+		t.Error("unexpected sign error:", err)
+	}
+
+	target = newTestTarget(t, ca, ca.issue(t, &newECDSAKey(t).PublicKey), encryptedPEM)
+	target.User.Signer = &SignerConfig{Type: SignerEncryptedPEM, PassphraseEnv: "TEST_KEY_PASSPHRASE"}
+	_, _, err = NewSign(target)
+	var identityErr *IdentityError
+	if !errors.As(err, &identityErr) || identityErr.Kind != ErrKeyMismatch || identityErr.Path != target.User.Cert {
+		t.Errorf("expected %v for %s, got %v", ErrKeyMismatch, target.User.Cert, err)
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sdk-go/service"
+)
+
+// Offline signing commands. A transaction goes through three signatures, one per stage:
+//
+//	propose  <function> [args...]          > proposal.json
+//	sign     proposal.json                 > proposal.sig     (on the air-gapped machine)
+//	continue proposal.json proposal.sig    > transaction.json
+//	sign     transaction.json              > transaction.sig
+//	continue transaction.json transaction.sig > commit.json
+//	sign     commit.json                   > commit.sig
+//	continue commit.json commit.sig        (prints the commit status)
+func init() {
+	commands["propose"] = command{
+		summary:  "create an unsigned proposal for offline signing",
+		args:     []string{"function"},
+		variadic: true,
+		connect:  connectUnsigned,
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			request, err := s.assets.PrepareOffline(args[0], args[1:]...)
+			if err != nil {
+				return nil, err
+			}
+			fmt.Fprintf(os.Stderr, "Created proposal for transaction %s; sign its digest and run continue\n", request.TransactionID)
+			return request, nil
+		},
+	}
+	commands["sign"] = command{
+		summary: "check an offline request and sign its digest with the local key, once confirmed",
+		args:    []string{"request-file"},
+		connect: connectNone,
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			if !s.assumeYes && args[0] == "-" {
+				// The confirmation is read from stdin
+				return nil, fmt.Errorf("%w: --yes is required to sign a request read from stdin", errUsage)
+			}
+			request, err := readRequest(args[0])
+			if err != nil {
+				return nil, err
+			}
+			content, err := request.Verify()
+			if err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(os.Stderr, "Signing %s digest for transaction %s\n", request.Stage, request.TransactionID)
+			printOfflineContent(os.Stderr, content)
+			if !s.assumeYes {
+				confirmed, err := confirm(os.Stdin, os.Stderr, "Sign? [y/N] ")
+				if err != nil {
+					return nil, err
+				}
+				if !confirmed {
+					return nil, errors.New("signing cancelled")
+				}
+			}
+			signature, err := s.credentials.Sign(request.Digest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign digest: %w", err)
+			}
+			return &offlineSignature{TxID: request.TransactionID, Stage: request.Stage, Signature: signature}, nil
+		},
+	}
+	commands["continue"] = command{
+		summary: "attach a signature to an offline request and run its stage",
+		args:    []string{"request-file", "signature-file"},
+		connect: connectUnsigned,
+		run: func(ctx context.Context, s *session, args []string) (any, error) {
+			request, err := readRequest(args[0])
+			if err != nil {
+				return nil, err
+			}
+			signature, err := readSignature(args[1])
+			if err != nil {
+				return nil, err
+			}
+
+			next, status, err := s.assets.ContinueOffline(ctx, request, signature)
+			if err != nil {
+				return nil, err
+			}
+			if next != nil {
+				fmt.Fprintf(os.Stderr, "Completed %s stage for transaction %s; sign the %s digest and run continue\n", request.Stage, request.TransactionID, next.Stage)
+				return next, nil
+			}
+			committed := &transaction{
+				TxID:           status.TransactionID,
+				BlockNumber:    status.BlockNumber,
+				ValidationCode: status.Code.String(),
+			}
+			if len(request.Result) > 0 {
+				committed.Result = string(request.Result)
+			}
+			return committed, nil
+		},
+	}
+}
+
+// offlineSignature is the result of the sign command.
+// The table output is the bare base64 signature so that it can be redirected to a file.
+type offlineSignature struct {
+	TxID      string `json:"txId"`
+	Stage     string `json:"stage"`
+	Signature []byte `json:"signature"`
+}
+
+// printOfflineContent shows what a signature of an offline request approves
+func printOfflineContent(w io.Writer, content *service.OfflineContent) {
+	fmt.Fprintf(w, "  channel:   %s\n", content.ChannelID)
+	if content.Function == "" {
+		return
+	}
+	fmt.Fprintf(w, "  chaincode: %s\n", content.ChaincodeID)
+	fmt.Fprintf(w, "  function:  %s\n", content.Function)
+	for i, arg := range content.Args {
+		fmt.Fprintf(w, "  arg %d:     %q\n", i, arg)
+	}
+}
+
+// confirm asks a yes or no question, and reads the answer from r
+func confirm(r io.Reader, w io.Writer, prompt string) (bool, error) {
+	fmt.Fprint(w, prompt)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func readRequest(path string) (*service.OfflineRequest, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+	return service.ReadOfflineRequest(bytes.NewReader(data))
+}
+
+// readSignature reads a base64 signature, either bare as written by sign or in its JSON output
+func readSignature(path string) ([]byte, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		signature := &offlineSignature{}
+		if err := json.Unmarshal([]byte(text), signature); err != nil {
+			return nil, fmt.Errorf("failed to parse signature file %s: %w", path, err)
+		}
+		return signature.Signature, nil
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature file %s: %w", path, err)
+	}
+	return signature, nil
+}
+
+// readInput reads a file, or stdin when path is -
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"sdk-go/service"
+)
+
+// Output formats accepted by --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// transaction is the result of a command that submits a transaction
+type transaction struct {
+	TxID           string `json:"txId"`
+	BlockNumber    uint64 `json:"blockNumber"`
+	ValidationCode string `json:"validationCode"`
+	Result         any    `json:"result,omitempty"`
+}
+
+// transfer is the result of the transfer command
+type transfer struct {
+	ID       string `json:"id"`
+	OldOwner string `json:"oldOwner"`
+	NewOwner string `json:"newOwner"`
+}
+
+// existence is the result of the exists command
+type existence struct {
+	ID     string `json:"id"`
+	Exists bool   `json:"exists"`
+}
+
+// printResult writes a command result to out in the requested format
+func printResult(out io.Writer, format string, result any) error {
+	// Offline requests are files for the next step, so they are always JSON
+	if request, ok := result.(*service.OfflineRequest); ok {
+		return request.Write(out)
+	}
+
+	if format == outputJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	printTable(w, result)
+	return w.Flush()
+}
+
+func printTable(w io.Writer, result any) {
+	switch result := result.(type) {
+	case []service.Asset:
+		printAssets(w, result...)
+	case *service.Asset:
+		printAssets(w, *result)
+	case service.Asset:
+		printAssets(w, result)
+	case existence:
+		fmt.Fprintln(w, "ID\tEXISTS")
+		fmt.Fprintf(w, "%s\t%s\n", result.ID, strconv.FormatBool(result.Exists))
+	case transfer:
+		fmt.Fprintln(w, "ID\tOLD OWNER\tNEW OWNER")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.ID, result.OldOwner, result.NewOwner)
+	case *offlineSignature:
+		fmt.Fprintln(w, base64.StdEncoding.EncodeToString(result.Signature))
+	case *transaction:
+		fmt.Fprintln(w, "TX ID\tBLOCK\tVALIDATION CODE")
+		fmt.Fprintf(w, "%s\t%d\t%s\n", result.TxID, result.BlockNumber, result.ValidationCode)
+		if result.Result != nil {
+			fmt.Fprintln(w)
+			printTable(w, result.Result)
+		}
+	default:
+		fmt.Fprintln(w, result)
+	}
+}
+
+func printAssets(w io.Writer, assets ...service.Asset) {
+	fmt.Fprintln(w, "ID\tCOLOR\tSIZE\tOWNER\tAPPRAISED VALUE")
+	for _, asset := range assets {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%d\n", asset.ID, asset.Color, asset.Size, asset.Owner, asset.AppraisedValue)
+	}
+}
+
+// printUsage describes the global flags and subcommands
+func printUsage(flags *flag.FlagSet) {
+	out := flags.Output()
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [arguments]\n\nCommands:\n", flags.Name())
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range commandNames() {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %s %s\t%s\n", name, cmd.usage(), cmd.summary)
+	}
+	w.Flush()
+	fmt.Fprintln(out, "\nFlags:")
+	flags.PrintDefaults()
+}
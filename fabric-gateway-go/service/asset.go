@@ -0,0 +1,11 @@
+package service
+
+// Asset describes basic details of what makes up a simple asset.
+// It matches the Asset struct of the asset-transfer-basic chaincode.
+type Asset struct {
+	AppraisedValue int    `json:"AppraisedValue"`
+	Color          string `json:"Color"`
+	ID             string `json:"ID"`
+	Owner          string `json:"Owner"`
+	Size           int    `json:"Size"`
+}
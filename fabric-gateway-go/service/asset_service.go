@@ -1,86 +1,337 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
 // AssetService handles interactions with the asset-transfer-basic chaincode
 type AssetService struct {
-	contract *client.Contract
+	gateway   *client.Gateway
+	contract  *client.Contract
+	events    chaincodeEvents
+	channel   string
+	chaincode string
+	retry     RetryPolicy
+	timeouts  Timeouts
+	out       io.Writer
 }
 
-// NewAssetService creates a new asset service instance
-func NewAssetService(gateway *client.Gateway) *AssetService {
-	network := gateway.GetNetwork("mychannel")
-	contract := network.GetContract("basic")
+// chaincodeEvents opens a chaincode event stream; it is client.Network.ChaincodeEvents outside tests
+type chaincodeEvents func(ctx context.Context, chaincodeName string, options ...client.ChaincodeEventsOption) (<-chan *client.ChaincodeEvent, error)
+
+// Option configures an AssetService
+type Option func(*AssetService)
+
+// WithChannel sets the channel name. The default is mychannel.
+func WithChannel(name string) Option {
+	return func(s *AssetService) {
+		s.channel = name
+	}
+}
 
-	return &AssetService{
-		contract: contract,
+// WithChaincode sets the chaincode name. The default is basic.
+func WithChaincode(name string) Option {
+	return func(s *AssetService) {
+		s.chaincode = name
+	}
+}
+
+// WithOutput sets where progress messages are written. The default is os.Stdout.
+func WithOutput(out io.Writer) Option {
+	return func(s *AssetService) {
+		s.out = out
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry transient gateway failures.
+// The default is DefaultRetryPolicy; use NoRetry to fail on the first error.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *AssetService) {
+		s.retry = policy
+	}
+}
+
+// WithTimeouts sets the default timeout applied to each attempt of each stage.
+// The default is DefaultTimeouts. Deadlines on the caller's context still apply.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *AssetService) {
+		s.timeouts = timeouts
+	}
+}
+
+// NewAssetService creates a new asset service instance
+func NewAssetService(gateway *client.Gateway, options ...Option) *AssetService {
+	service := &AssetService{
+		gateway:   gateway,
+		channel:   "mychannel",
+		chaincode: "basic",
+		retry:     DefaultRetryPolicy(),
+		timeouts:  DefaultTimeouts(),
+		out:       os.Stdout,
+	}
+	for _, option := range options {
+		option(service)
 	}
+
+	network := gateway.GetNetwork(service.channel)
+	service.contract = network.GetContract(service.chaincode)
+	service.events = network.ChaincodeEvents
+	return service
 }
 
 // InitLedger initializes the ledger with sample assets
-func (s *AssetService) InitLedger() error {
-	fmt.Println("Submitting InitLedger transaction...")
-	_, err := s.contract.SubmitTransaction("InitLedger")
-	if err != nil {
+func (s *AssetService) InitLedger(ctx context.Context) error {
+	fmt.Fprintln(s.out, "Submitting InitLedger transaction...")
+	if _, err := s.submit(ctx, "InitLedger"); err != nil {
 		return fmt.Errorf("failed to init ledger: %w", err)
 	}
-	fmt.Println("✓ Ledger initialized successfully")
+	fmt.Fprintln(s.out, "✓ Ledger initialized successfully")
 	return nil
 }
 
-// CreateAsset creates a new asset on the ledger
-func (s *AssetService) CreateAsset(id, color, size, owner, value string) error {
-	fmt.Printf("Creating asset %s...\n", id)
-	_, err := s.contract.SubmitTransaction("CreateAsset", id, color, size, owner, value)
+// InitLedgerAsync submits the InitLedger transaction without waiting for it to commit
+func (s *AssetService) InitLedgerAsync(ctx context.Context) (*Commit, error) {
+	_, commit, err := s.submitAsync(ctx, "InitLedger")
 	if err != nil {
-		return fmt.Errorf("failed to create asset %s: %w", id, err)
+		return nil, fmt.Errorf("failed to init ledger: %w", err)
+	}
+	return commit, nil
+}
+
+// CreateAsset creates a new asset on the ledger.
+// Returns an error matching ErrAssetAlreadyExists if the asset ID is taken.
+func (s *AssetService) CreateAsset(ctx context.Context, asset Asset) error {
+	fmt.Fprintf(s.out, "Creating asset %s...\n", asset.ID)
+	if _, err := s.submit(ctx, "CreateAsset", assetArguments(asset)...); err != nil {
+		return fmt.Errorf("failed to create asset %s: %w", asset.ID, newAssetError(asset.ID, err))
 	}
-	fmt.Printf("✓ Asset %s created successfully\n", id)
+	fmt.Fprintf(s.out, "✓ Asset %s created successfully\n", asset.ID)
 	return nil
 }
 
+// CreateAssetAsync submits the CreateAsset transaction without waiting for it to commit
+func (s *AssetService) CreateAssetAsync(ctx context.Context, asset Asset) (*Commit, error) {
+	_, commit, err := s.submitAsync(ctx, "CreateAsset", assetArguments(asset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create asset %s: %w", asset.ID, newAssetError(asset.ID, err))
+	}
+	return commit, nil
+}
+
 // GetAllAssets returns all assets from the ledger
-func (s *AssetService) GetAllAssets() (string, error) {
-	fmt.Println("Querying all assets...")
-	result, err := s.contract.EvaluateTransaction("GetAllAssets")
+func (s *AssetService) GetAllAssets(ctx context.Context) ([]Asset, error) {
+	fmt.Fprintln(s.out, "Querying all assets...")
+	result, err := s.evaluate(ctx, "GetAllAssets")
 	if err != nil {
-		return "", fmt.Errorf("failed to get all assets: %w", err)
+		return nil, fmt.Errorf("failed to get all assets: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, nil
 	}
-	return string(result), nil
+
+	var assets []Asset
+	if err := json.Unmarshal(result, &assets); err != nil {
+		return nil, fmt.Errorf("failed to parse assets: %w", err)
+	}
+	return assets, nil
 }
 
-// ReadAsset returns a specific asset by ID
-func (s *AssetService) ReadAsset(id string) (string, error) {
-	fmt.Printf("Reading asset %s...\n", id)
-	result, err := s.contract.EvaluateTransaction("ReadAsset", id)
+// ReadAsset returns a specific asset by ID.
+// Returns an error matching ErrAssetNotFound if the asset does not exist.
+func (s *AssetService) ReadAsset(ctx context.Context, id string) (*Asset, error) {
+	fmt.Fprintf(s.out, "Reading asset %s...\n", id)
+	result, err := s.evaluate(ctx, "ReadAsset", id)
 	if err != nil {
-		return "", fmt.Errorf("failed to read asset %s: %w", id, err)
+		return nil, fmt.Errorf("failed to read asset %s: %w", id, newAssetError(id, err))
 	}
-	return string(result), nil
+
+	asset := &Asset{}
+	if err := json.Unmarshal(result, asset); err != nil {
+		return nil, fmt.Errorf("failed to parse asset %s: %w", id, err)
+	}
+	return asset, nil
 }
 
-// UpdateAsset updates an existing asset
-func (s *AssetService) UpdateAsset(id, color, size, owner, value string) error {
-	fmt.Printf("Updating asset %s...\n", id)
-	_, err := s.contract.SubmitTransaction("UpdateAsset", id, color, size, owner, value)
+// AssetExists reports whether an asset with the given ID exists on the ledger
+func (s *AssetService) AssetExists(ctx context.Context, id string) (bool, error) {
+	fmt.Fprintf(s.out, "Checking asset %s...\n", id)
+	result, err := s.evaluate(ctx, "AssetExists", id)
 	if err != nil {
-		return fmt.Errorf("failed to update asset %s: %w", id, err)
+		return false, fmt.Errorf("failed to check asset %s: %w", id, newAssetError(id, err))
+	}
+
+	exists, err := strconv.ParseBool(string(result))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse AssetExists result %q: %w", result, err)
+	}
+	return exists, nil
+}
+
+// UpdateAsset updates an existing asset.
+// Returns an error matching ErrAssetNotFound if the asset does not exist.
+func (s *AssetService) UpdateAsset(ctx context.Context, asset Asset) error {
+	fmt.Fprintf(s.out, "Updating asset %s...\n", asset.ID)
+	if _, err := s.submit(ctx, "UpdateAsset", assetArguments(asset)...); err != nil {
+		return fmt.Errorf("failed to update asset %s: %w", asset.ID, newAssetError(asset.ID, err))
 	}
-	fmt.Printf("✓ Asset %s updated successfully\n", id)
+	fmt.Fprintf(s.out, "✓ Asset %s updated successfully\n", asset.ID)
 	return nil
 }
 
-// DeleteAsset deletes an asset from the ledger
-func (s *AssetService) DeleteAsset(id string) error {
-	fmt.Printf("Deleting asset %s...\n", id)
-	_, err := s.contract.SubmitTransaction("DeleteAsset", id)
+// UpdateAssetAsync submits the UpdateAsset transaction without waiting for it to commit
+func (s *AssetService) UpdateAssetAsync(ctx context.Context, asset Asset) (*Commit, error) {
+	_, commit, err := s.submitAsync(ctx, "UpdateAsset", assetArguments(asset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update asset %s: %w", asset.ID, newAssetError(asset.ID, err))
+	}
+	return commit, nil
+}
+
+// TransferAsset changes the owner of an asset and returns the previous owner.
+// Returns an error matching ErrAssetNotFound if the asset does not exist.
+func (s *AssetService) TransferAsset(ctx context.Context, id, newOwner string) (string, error) {
+	fmt.Fprintf(s.out, "Transferring asset %s to %s...\n", id, newOwner)
+	result, err := s.submit(ctx, "TransferAsset", id, newOwner)
 	if err != nil {
-		return fmt.Errorf("failed to delete asset %s: %w", id, err)
+		return "", fmt.Errorf("failed to transfer asset %s: %w", id, newAssetError(id, err))
 	}
-	fmt.Printf("✓ Asset %s deleted successfully\n", id)
+	oldOwner := string(result)
+	fmt.Fprintf(s.out, "✓ Asset %s transferred from %s to %s\n", id, oldOwner, newOwner)
+	return oldOwner, nil
+}
+
+// TransferAssetAsync submits the TransferAsset transaction without waiting for it to commit.
+// The previous owner is available as soon as the transaction is endorsed.
+func (s *AssetService) TransferAssetAsync(ctx context.Context, id, newOwner string) (string, *Commit, error) {
+	result, commit, err := s.submitAsync(ctx, "TransferAsset", id, newOwner)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to transfer asset %s: %w", id, newAssetError(id, err))
+	}
+	return string(result), commit, nil
+}
+
+// DeleteAsset deletes an asset from the ledger.
+// Returns an error matching ErrAssetNotFound if the asset does not exist.
+func (s *AssetService) DeleteAsset(ctx context.Context, id string) error {
+	fmt.Fprintf(s.out, "Deleting asset %s...\n", id)
+	if _, err := s.submit(ctx, "DeleteAsset", id); err != nil {
+		return fmt.Errorf("failed to delete asset %s: %w", id, newAssetError(id, err))
+	}
+	fmt.Fprintf(s.out, "✓ Asset %s deleted successfully\n", id)
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteAssetAsync submits the DeleteAsset transaction without waiting for it to commit
+func (s *AssetService) DeleteAssetAsync(ctx context.Context, id string) (*Commit, error) {
+	_, commit, err := s.submitAsync(ctx, "DeleteAsset", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete asset %s: %w", id, newAssetError(id, err))
+	}
+	return commit, nil
+}
+
+// evaluate evaluates a transaction function, retrying transient failures.
+// Each attempt is bounded by the Evaluate timeout.
+func (s *AssetService) evaluate(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var result []byte
+	err := s.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, s.timeouts.Evaluate)
+		defer cancel()
+		result, err = s.contract.EvaluateWithContext(ctx, name, client.WithArguments(args...))
+		return err
+	})
+	return result, err
+}
+
+// submitAsync endorses and submits a transaction without waiting for it to commit.
+// Endorse and submit are retried separately so that a retried submit keeps the same transaction ID.
+func (s *AssetService) submitAsync(ctx context.Context, name string, args ...string) ([]byte, *Commit, error) {
+	proposal, err := s.contract.NewProposal(name, client.WithArguments(args...))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transaction *client.Transaction
+	err = s.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, s.timeouts.Endorse)
+		defer cancel()
+		transaction, err = proposal.EndorseWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var commit *client.Commit
+	err = s.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, s.timeouts.Submit)
+		defer cancel()
+		commit, err = transaction.SubmitWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transaction.Result(), &Commit{commit: commit, retry: s.retry, timeout: s.timeouts.CommitStatus}, nil
+}
+
+// submit submits a transaction and waits for it to commit, resubmitting it as Resubmit does.
+func (s *AssetService) submit(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var result []byte
+	_, err := s.Resubmit(ctx, func(ctx context.Context) (commit *Commit, err error) {
+		result, commit, err = s.submitAsync(ctx, name, args...)
+		return commit, err
+	})
+	return result, err
+}
+
+// Resubmit calls send to submit a transaction with one of the Async methods, waits for it to commit and logs where
+// it landed. A transaction that fails validation with a retryable code, such as MVCC_READ_CONFLICT, is endorsed and
+// submitted again as a new transaction by calling send again, according to the service's RetryPolicy.
+// Errors from send are returned without calling it again, since the Async methods retry transient failures themselves.
+// The retries of send, of the commit status and the resubmissions share one budget of MaxAttempts-1 retries when send
+// uses the context it is given, so the transaction is endorsed at most MaxAttempts times.
+// It returns the status of the last transaction, together with a *CommitFailedError if it failed validation.
+func (s *AssetService) Resubmit(ctx context.Context, send func(ctx context.Context) (*Commit, error)) (*client.Status, error) {
+	ctx = s.retry.withBudget(ctx)
+	var status *client.Status
+	err := s.retry.do(ctx, func() error {
+		commit, err := send(ctx)
+		if err != nil {
+			return noRetry{err}
+		}
+
+		status, err = commit.Status(ctx)
+		var commitErr *CommitFailedError
+		if err != nil && !errors.As(err, &commitErr) {
+			return noRetry{err}
+		}
+		if err != nil {
+			fmt.Fprintf(s.out, "  transaction %s failed validation with %s\n", status.TransactionID, status.Code)
+			return err
+		}
+		fmt.Fprintf(s.out, "  transaction %s committed in block %d\n", status.TransactionID, status.BlockNumber)
+		return nil
+	})
+	return status, unwrapNoRetry(err)
+}
+
+// assetArguments returns the chaincode arguments for CreateAsset and UpdateAsset
+func assetArguments(asset Asset) []string {
+	return []string{
+		asset.ID,
+		asset.Color,
+		strconv.Itoa(asset.Size),
+		asset.Owner,
+		strconv.Itoa(asset.AppraisedValue),
+	}
+}
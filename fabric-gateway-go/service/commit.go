@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"google.golang.org/grpc"
+)
+
+// Commit is a handle to a transaction submitted with one of the Async methods.
+type Commit struct {
+	commit  committer
+	retry   RetryPolicy
+	timeout time.Duration
+}
+
+// committer waits for the status of a submitted transaction; it is *client.Commit outside tests
+type committer interface {
+	TransactionID() string
+	StatusWithContext(ctx context.Context, opts ...grpc.CallOption) (*client.Status, error)
+}
+
+// TransactionID returns the ID of the submitted transaction
+func (c *Commit) TransactionID() string {
+	return c.commit.TransactionID()
+}
+
+// Status waits for the transaction to commit and returns its validation code and block number.
+// Transient failures to obtain the status are retried according to the service's RetryPolicy,
+// and each attempt is bounded by the CommitStatus timeout.
+// If the transaction committed with a validation code other than VALID, the status is returned
+// together with a *CommitFailedError, which matches ErrCommitFailed.
+func (c *Commit) Status(ctx context.Context) (*client.Status, error) {
+	var status *client.Status
+	err := c.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, c.timeout)
+		defer cancel()
+		status, err = c.commit.StatusWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit status for transaction %s: %w", c.TransactionID(), err)
+	}
+	if !status.Successful {
+		return status, &CommitFailedError{Status: status}
+	}
+	return status, nil
+}
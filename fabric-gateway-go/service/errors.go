@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/status"
+)
+
+// Errors reported by the asset-transfer-basic chaincode, usable with errors.Is.
+var (
+	ErrAssetNotFound      = errors.New("asset not found")
+	ErrAssetAlreadyExists = errors.New("asset already exists")
+)
+
+// ErrCommitFailed is returned when a transaction is ordered into a block but
+// fails validation, for example with MVCC_READ_CONFLICT.
+var ErrCommitFailed = errors.New("transaction failed to commit")
+
+// CommitFailedError carries the commit status of a transaction that failed validation.
+type CommitFailedError struct {
+	Status *client.Status
+}
+
+func (e *CommitFailedError) Error() string {
+	return fmt.Sprintf("transaction %s failed to commit in block %d with status code %s",
+		e.Status.TransactionID, e.Status.BlockNumber, e.Status.Code)
+}
+
+func (e *CommitFailedError) Unwrap() error {
+	return ErrCommitFailed
+}
+
+// AssetError is returned when a chaincode call for a specific asset fails.
+// Kind is one of the errors above, or nil if the failure could not be classified.
+type AssetError struct {
+	ID   string
+	Kind error
+	Err  error
+}
+
+func (e *AssetError) Error() string {
+	if e.Kind == nil {
+		return e.Err.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *AssetError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// chaincodeMessages maps chaincode error text to error kinds.
+var chaincodeMessages = []struct {
+	text string
+	kind error
+}{
+	{"does not exist", ErrAssetNotFound},
+	{"already exists", ErrAssetAlreadyExists},
+}
+
+// newAssetError classifies a gateway error using the chaincode messages carried
+// in the gRPC status details returned by the gateway peer.
+func newAssetError(id string, err error) error {
+	return &AssetError{ID: id, Kind: errorKind(err), Err: err}
+}
+
+func errorKind(err error) error {
+	for _, message := range errorMessages(err) {
+		for _, candidate := range chaincodeMessages {
+			if strings.Contains(message, candidate.text) {
+				return candidate.kind
+			}
+		}
+	}
+	return nil
+}
+
+// errorMessages returns the messages of all gateway error details, falling back
+// to the status message when the error carries no details.
+func errorMessages(err error) []string {
+	statusErr, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+
+	var messages []string
+	for _, detail := range statusErr.Details() {
+		if errorDetail, ok := detail.(*gateway.ErrorDetail); ok {
+			messages = append(messages, errorDetail.GetMessage())
+		}
+	}
+	if len(messages) == 0 {
+		messages = append(messages, statusErr.Message())
+	}
+	return messages
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func gatewayError(t *testing.T, message string) error {
+	statusErr, err := status.New(codes.Aborted, "failed to endorse transaction").WithDetails(&gateway.ErrorDetail{
+		Address: "peer0.org1.example.com:7051",
+		MspId:   "Org1MSP",
+		Message: message,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return statusErr.Err()
+}
+
+func Test_NewAssetError(t *testing.T) {
+	for name, testCase := range map[string]struct {
+		err      error
+		expected error
+	}{
+		"not found":      {gatewayError(t, "chaincode response 500, the asset asset9 does not exist"), ErrAssetNotFound},
+		"already exists": {gatewayError(t, "chaincode response 500, the asset asset1 already exists"), ErrAssetAlreadyExists},
+		"status message": {status.Error(codes.Unknown, "the asset asset9 does not exist"), ErrAssetNotFound},
+		"other":          {gatewayError(t, "chaincode response 500, boom"), nil},
+		"not gRPC":       {errors.New("the asset asset9 does not exist"), nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := newAssetError("asset9", testCase.err)
+			if testCase.expected != nil && !errors.Is(err, testCase.expected) {
+				t.Errorf("expected %v, got %v", testCase.expected, err)
+			}
+			if testCase.expected == nil && (errors.Is(err, ErrAssetNotFound) || errors.Is(err, ErrAssetAlreadyExists)) {
+				t.Errorf("expected unclassified error, got %v", err)
+			}
+			if !errors.Is(err, testCase.err) {
+				t.Error("expected original error to be wrapped")
+			}
+		})
+	}
+}
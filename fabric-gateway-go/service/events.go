@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// Event names emitted by the asset-transfer-events chaincode.
+// The payload of each event is the JSON asset as written (or, for AssetDeleted, as last stored).
+const (
+	AssetCreated     = "CreateAsset"
+	AssetUpdated     = "UpdateAsset"
+	AssetTransferred = "TransferAsset"
+	AssetDeleted     = "DeleteAsset"
+)
+
+// AssetEvent is a chaincode event decoded into an Asset
+type AssetEvent struct {
+	Type          string // One of AssetCreated, AssetUpdated, AssetTransferred or AssetDeleted
+	Asset         Asset
+	TransactionID string
+	BlockNumber   uint64
+}
+
+// Checkpointer records the last event handled so that a subscription resumes after it.
+// client.FileCheckpointer satisfies this interface.
+type Checkpointer interface {
+	client.Checkpoint
+	CheckpointChaincodeEvent(event *client.ChaincodeEvent) error
+}
+
+// memoryCheckpointer adapts client.InMemoryCheckpointer to Checkpointer.
+// It lets a subscription without a durable checkpoint resume after a reconnect.
+type memoryCheckpointer struct {
+	client.InMemoryCheckpointer
+}
+
+func (c *memoryCheckpointer) CheckpointChaincodeEvent(event *client.ChaincodeEvent) error {
+	c.InMemoryCheckpointer.CheckpointChaincodeEvent(event)
+	return nil
+}
+
+// EventOption configures an asset event subscription
+type EventOption func(*eventOptions)
+
+type eventOptions struct {
+	checkpointer   Checkpointer
+	checkpointFile string
+	startBlock     *uint64
+	buffer         int
+}
+
+// WithCheckpointer stores the subscription position in checkpointer after each event is handled.
+// The caller owns the checkpointer and must close it.
+func WithCheckpointer(checkpointer Checkpointer) EventOption {
+	return func(o *eventOptions) {
+		o.checkpointer = checkpointer
+	}
+}
+
+// WithCheckpointFile stores the subscription position in a file using client.NewFileCheckpointer.
+// The file is created if it does not exist and closed when the subscription ends.
+func WithCheckpointFile(path string) EventOption {
+	return func(o *eventOptions) {
+		o.checkpointFile = path
+	}
+}
+
+// WithStartBlock reads events from the given block when the checkpoint is empty.
+// Without it, an empty checkpoint starts at the next block to be committed.
+func WithStartBlock(blockNumber uint64) EventOption {
+	return func(o *eventOptions) {
+		o.startBlock = &blockNumber
+	}
+}
+
+// WithEventBuffer sets the capacity of the channel returned by SubscribeAssetEvents. The default is 0.
+func WithEventBuffer(size int) EventOption {
+	return func(o *eventOptions) {
+		o.buffer = size
+	}
+}
+
+// HandleAssetEvents calls handler for each asset event until ctx is done or handler fails.
+// The checkpoint is updated only after handler returns nil, so an event whose handler failed
+// is delivered again when the subscription restarts from the same checkpoint.
+// Events with an unknown name or a payload that is not an asset are reported and checkpointed
+// without calling handler.
+// Interrupted event streams are reopened from the checkpoint according to the service's RetryPolicy.
+// It returns nil when ctx is canceled, and the handler error or the last connection error otherwise.
+func (s *AssetService) HandleAssetEvents(ctx context.Context, handler func(AssetEvent) error, options ...EventOption) error {
+	opts := eventOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	checkpointer := opts.checkpointer
+	if opts.checkpointFile != "" {
+		fileCheckpointer, err := client.NewFileCheckpointer(opts.checkpointFile)
+		if err != nil {
+			return fmt.Errorf("failed to open checkpoint file: %w", err)
+		}
+		defer fileCheckpointer.Close()
+		checkpointer = fileCheckpointer
+	}
+	if checkpointer == nil {
+		checkpointer = &memoryCheckpointer{}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+		var delivered bool
+		err := s.retry.do(ctx, func() error {
+			delivered = false
+			var eventOptions []client.ChaincodeEventsOption
+			if opts.startBlock != nil {
+				eventOptions = append(eventOptions, client.WithStartBlock(*opts.startBlock))
+			}
+			// The checkpoint, once set, takes precedence over the start block
+			eventOptions = append(eventOptions, client.WithCheckpoint(checkpointer))
+
+			fmt.Fprintf(s.out, "Listening for %s chaincode events from block %d...\n", s.chaincode, checkpointer.BlockNumber())
+			events, err := s.events(ctx, s.chaincode, eventOptions...)
+			if err != nil {
+				return err
+			}
+
+			for event := range events {
+				if assetEvent, err := parseAssetEvent(event); err != nil {
+					// The event would stop every subscription from this checkpoint, so skip it
+					fmt.Fprintf(s.out, "Skipping event: %v\n", err)
+				} else if err := handler(assetEvent); err != nil {
+					return noRetry{err}
+				}
+				if err := checkpointer.CheckpointChaincodeEvent(event); err != nil {
+					return noRetry{fmt.Errorf("failed to checkpoint event: %w", err)}
+				}
+				delivered = true
+			}
+
+			// The event channel closes when the stream fails or ctx is done.
+			// A stream that made progress is reopened straight away with a fresh retry budget.
+			if delivered {
+				return noRetry{errEventStreamClosed}
+			}
+			return errEventStreamClosed
+		})
+
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case delivered && errors.Is(err, errEventStreamClosed):
+			continue
+		default:
+			return unwrapNoRetry(err)
+		}
+	}
+}
+
+// SubscribeAssetEvents delivers asset events on the returned channel until ctx is done.
+// An event is checkpointed once it has been received from the channel.
+// If the subscription stops for any other reason, the error is sent on the error channel.
+// Both channels are closed when the subscription ends.
+func (s *AssetService) SubscribeAssetEvents(ctx context.Context, options ...EventOption) (<-chan AssetEvent, <-chan error) {
+	opts := eventOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	events := make(chan AssetEvent, opts.buffer)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(events)
+		err := s.HandleAssetEvents(ctx, func(event AssetEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, options...)
+		if err != nil && ctx.Err() == nil {
+			errs <- err
+		}
+	}()
+	return events, errs
+}
+
+// errEventStreamClosed is returned when the gateway closes an event stream, which is retried
+var errEventStreamClosed = errors.New("chaincode event stream closed")
+
+// parseAssetEvent checks the name of an asset chaincode event and decodes its payload
+func parseAssetEvent(event *client.ChaincodeEvent) (AssetEvent, error) {
+	result := AssetEvent{
+		Type:          event.EventName,
+		TransactionID: event.TransactionID,
+		BlockNumber:   event.BlockNumber,
+	}
+	switch event.EventName {
+	case AssetCreated, AssetUpdated, AssetTransferred, AssetDeleted:
+	default:
+		return result, fmt.Errorf("unknown event %q in transaction %s", event.EventName, event.TransactionID)
+	}
+	if err := json.Unmarshal(event.Payload, &result.Asset); err != nil {
+		return result, fmt.Errorf("failed to parse %s event payload in transaction %s: %w", event.EventName, event.TransactionID, err)
+	}
+	return result, nil
+}
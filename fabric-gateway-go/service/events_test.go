@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+func assetEvent(t *testing.T, name, txID string, block uint64, asset Asset) *client.ChaincodeEvent {
+	t.Helper()
+	payload, err := json.Marshal(asset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &client.ChaincodeEvent{BlockNumber: block, TransactionID: txID, ChaincodeName: "basic", EventName: name, Payload: payload}
+}
+
+// newEventService returns a service whose event streams replay each batch in turn and then close
+func newEventService(batches ...[]*client.ChaincodeEvent) (*AssetService, *int) {
+	calls := 0
+	service := &AssetService{
+		chaincode: "basic",
+		retry:     RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		out:       io.Discard,
+	}
+	service.events = func(ctx context.Context, _ string, _ ...client.ChaincodeEventsOption) (<-chan *client.ChaincodeEvent, error) {
+		events := make(chan *client.ChaincodeEvent, 10)
+		if calls < len(batches) {
+			for _, event := range batches[calls] {
+				events <- event
+			}
+		}
+		calls++
+		close(events)
+		return events, nil
+	}
+	return service, &calls
+}
+
+func Test_HandleAssetEvents(t *testing.T) {
+	asset := Asset{ID: "asset1", Color: "blue", Size: 5, Owner: "Tomoko", AppraisedValue: 300}
+	service, calls := newEventService(
+		[]*client.ChaincodeEvent{
+			assetEvent(t, AssetCreated, "tx1", 10, asset),
+			assetEvent(t, AssetTransferred, "tx2", 11, asset),
+		},
+		[]*client.ChaincodeEvent{
+			assetEvent(t, AssetDeleted, "tx3", 12, asset),
+		},
+	)
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	var received []AssetEvent
+	err := service.HandleAssetEvents(context.Background(), func(event AssetEvent) error {
+		received = append(received, event)
+		return nil
+	}, WithCheckpointFile(checkpointFile))
+
+	if !errors.Is(err, errEventStreamClosed) {
+		t.Errorf("expected %v once retries are exhausted, got %v", errEventStreamClosed, err)
+	}
+	if len(received) != 3 {
+		t.Fatalf("expected 3 events across reconnects, got %d", len(received))
+	}
+	if received[0].Type != AssetCreated || received[0].Asset != asset || received[0].TransactionID != "tx1" || received[0].BlockNumber != 10 {
+		t.Errorf("unexpected first event: %+v", received[0])
+	}
+	if *calls != 4 {
+		t.Errorf("expected 2 streams with events and 2 empty retries, got %d streams", *calls)
+	}
+
+	checkpointer, err := client.NewFileCheckpointer(checkpointFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpointer.Close()
+	if checkpointer.BlockNumber() != 12 || checkpointer.TransactionID() != "tx3" {
+		t.Errorf("expected checkpoint at block 12 after tx3, got block %d after %s", checkpointer.BlockNumber(), checkpointer.TransactionID())
+	}
+}
+
+func Test_HandleAssetEvents_HandlerError(t *testing.T) {
+	asset := Asset{ID: "asset1"}
+	service, _ := newEventService([]*client.ChaincodeEvent{
+		assetEvent(t, AssetCreated, "tx1", 10, asset),
+		assetEvent(t, AssetUpdated, "tx2", 11, asset),
+	})
+	checkpointer := &memoryCheckpointer{}
+	cacheDown := errors.New("cache unavailable")
+
+	err := service.HandleAssetEvents(context.Background(), func(event AssetEvent) error {
+		if event.TransactionID == "tx2" {
+			return cacheDown
+		}
+		return nil
+	}, WithCheckpointer(checkpointer))
+
+	if !errors.Is(err, cacheDown) {
+		t.Errorf("expected handler error, got %v", err)
+	}
+	if checkpointer.TransactionID() != "tx1" {
+		t.Errorf("expected checkpoint to stop at tx1, got %s", checkpointer.TransactionID())
+	}
+}
+
+func Test_HandleAssetEvents_SkipsInvalidEvents(t *testing.T) {
+	asset := Asset{ID: "asset1"}
+	malformed := assetEvent(t, AssetUpdated, "tx2", 11, asset)
+	malformed.Payload = []byte("not an asset")
+	service, _ := newEventService([]*client.ChaincodeEvent{
+		assetEvent(t, AssetCreated, "tx1", 10, asset),
+		malformed,
+		assetEvent(t, "AssetAppraised", "tx3", 12, asset),
+		assetEvent(t, AssetDeleted, "tx4", 13, asset),
+	})
+	var out strings.Builder
+	service.out = &out
+	checkpointer := &memoryCheckpointer{}
+
+	var received []string
+	err := service.HandleAssetEvents(context.Background(), func(event AssetEvent) error {
+		received = append(received, event.TransactionID)
+		return nil
+	}, WithCheckpointer(checkpointer))
+
+	if !errors.Is(err, errEventStreamClosed) {
+		t.Errorf("expected %v once retries are exhausted, got %v", errEventStreamClosed, err)
+	}
+	if !slices.Equal(received, []string{"tx1", "tx4"}) {
+		t.Errorf("expected only the valid events tx1 and tx4, got %v", received)
+	}
+	if checkpointer.TransactionID() != "tx4" {
+		t.Errorf("expected checkpoint past the skipped events at tx4, got %s", checkpointer.TransactionID())
+	}
+	for _, expected := range []string{"tx2", "AssetAppraised"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected skipped %s to be reported, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func Test_SubscribeAssetEvents(t *testing.T) {
+	asset := Asset{ID: "asset1"}
+	service, _ := newEventService([]*client.ChaincodeEvent{
+		assetEvent(t, AssetCreated, "tx1", 10, asset),
+		{BlockNumber: 11, TransactionID: "tx2", EventName: AssetUpdated, Payload: []byte("not json")},
+	})
+
+	events, errs := service.SubscribeAssetEvents(context.Background())
+	var received []AssetEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	if len(received) != 1 || received[0].TransactionID != "tx1" {
+		t.Errorf("expected only tx1, got %+v", received)
+	}
+	if err := <-errs; err == nil {
+		t.Error("expected error for malformed payload")
+	}
+}
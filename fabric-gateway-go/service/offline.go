@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// Stages of an offline-signed transaction. Each stage produces a new digest to sign.
+const (
+	OfflineProposal    = "proposal"    // Signed proposal is sent for endorsement
+	OfflineTransaction = "transaction" // Signed endorsed transaction is submitted to the orderer
+	OfflineCommit      = "commit"      // Signed commit status request waits for the transaction to commit
+)
+
+// ErrInvalidOfflineRequest is returned for a malformed or out of order offline request
+var ErrInvalidOfflineRequest = errors.New("invalid offline request")
+
+// OfflineRequest is an unsigned gateway request waiting for an external signature.
+// It serializes to JSON, with Bytes, Digest and Result encoded in base64, so that it can be
+// carried to an air-gapped machine. The signature must be an ASN.1 DER ECDSA signature of
+// Digest with a low S value, as produced by identity.NewPrivateKeySign.
+type OfflineRequest struct {
+	Stage         string `json:"stage"`
+	TransactionID string `json:"txId"`
+	Function      string `json:"function,omitempty"`
+	Result        []byte `json:"result,omitempty"` // Transaction function result, once endorsed
+	Bytes         []byte `json:"bytes"`
+	Digest        []byte `json:"digest"`
+}
+
+// ReadOfflineRequest reads a JSON offline request
+func ReadOfflineRequest(r io.Reader) (*OfflineRequest, error) {
+	request := &OfflineRequest{}
+	if err := json.NewDecoder(r).Decode(request); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOfflineRequest, err)
+	}
+	if len(request.Bytes) == 0 {
+		return nil, fmt.Errorf("%w: no request bytes", ErrInvalidOfflineRequest)
+	}
+	return request, nil
+}
+
+// Write writes the request as indented JSON
+func (r *OfflineRequest) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// OfflineContent is what a signature of an offline request approves, decoded from the request bytes
+type OfflineContent struct {
+	ChannelID   string
+	ChaincodeID string   // Empty for a commit status request
+	Function    string   // Empty for a commit status request
+	Args        []string // Transient data is not included
+}
+
+// Verify decodes the request bytes, checks that the digest, transaction ID and function of the request
+// describe them, and returns their content. The other fields of a request are not covered by its
+// signature, so check the content before signing the digest.
+func (r *OfflineRequest) Verify() (*OfflineContent, error) {
+	signed, txID, content, err := r.decode()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidOfflineRequest, r.Stage, err)
+	}
+	if !bytes.Equal(hash.SHA256(signed), r.Digest) {
+		return nil, fmt.Errorf("%w: digest does not match the %s", ErrInvalidOfflineRequest, r.Stage)
+	}
+	if txID != r.TransactionID {
+		return nil, fmt.Errorf("%w: %s is for transaction %s, not %s", ErrInvalidOfflineRequest, r.Stage, txID, r.TransactionID)
+	}
+	if r.Function != "" && content.Function != "" && r.Function != content.Function {
+		return nil, fmt.Errorf("%w: %s calls %s, not %s", ErrInvalidOfflineRequest, r.Stage, content.Function, r.Function)
+	}
+	return content, nil
+}
+
+// decode returns the signed bytes of the request, which its digest is the hash of, and its transaction ID and content
+func (r *OfflineRequest) decode() ([]byte, string, *OfflineContent, error) {
+	switch r.Stage {
+	case OfflineProposal:
+		proposedTransaction := &gateway.ProposedTransaction{}
+		if err := proto.Unmarshal(r.Bytes, proposedTransaction); err != nil {
+			return nil, "", nil, err
+		}
+		signed := proposedTransaction.GetProposal().GetProposalBytes()
+		proposal := &peer.Proposal{}
+		if err := proto.Unmarshal(signed, proposal); err != nil {
+			return nil, "", nil, err
+		}
+		header := &common.Header{}
+		if err := proto.Unmarshal(proposal.GetHeader(), header); err != nil {
+			return nil, "", nil, err
+		}
+		payload := &peer.ChaincodeProposalPayload{}
+		if err := proto.Unmarshal(proposal.GetPayload(), payload); err != nil {
+			return nil, "", nil, err
+		}
+		return decodeInvocation(signed, header.GetChannelHeader(), payload.GetInput())
+
+	case OfflineTransaction:
+		preparedTransaction := &gateway.PreparedTransaction{}
+		if err := proto.Unmarshal(r.Bytes, preparedTransaction); err != nil {
+			return nil, "", nil, err
+		}
+		signed := preparedTransaction.GetEnvelope().GetPayload()
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(signed, payload); err != nil {
+			return nil, "", nil, err
+		}
+		transaction := &peer.Transaction{}
+		if err := proto.Unmarshal(payload.GetData(), transaction); err != nil {
+			return nil, "", nil, err
+		}
+		if len(transaction.GetActions()) == 0 {
+			return nil, "", nil, errors.New("no chaincode action")
+		}
+		actionPayload := &peer.ChaincodeActionPayload{}
+		if err := proto.Unmarshal(transaction.GetActions()[0].GetPayload(), actionPayload); err != nil {
+			return nil, "", nil, err
+		}
+		proposalPayload := &peer.ChaincodeProposalPayload{}
+		if err := proto.Unmarshal(actionPayload.GetChaincodeProposalPayload(), proposalPayload); err != nil {
+			return nil, "", nil, err
+		}
+		return decodeInvocation(signed, payload.GetHeader().GetChannelHeader(), proposalPayload.GetInput())
+
+	case OfflineCommit:
+		signedRequest := &gateway.SignedCommitStatusRequest{}
+		if err := proto.Unmarshal(r.Bytes, signedRequest); err != nil {
+			return nil, "", nil, err
+		}
+		signed := signedRequest.GetRequest()
+		request := &gateway.CommitStatusRequest{}
+		if err := proto.Unmarshal(signed, request); err != nil {
+			return nil, "", nil, err
+		}
+		return signed, request.GetTransactionId(), &OfflineContent{ChannelID: request.GetChannelId()}, nil
+
+	default:
+		return nil, "", nil, errors.New("unknown stage")
+	}
+}
+
+// decodeInvocation decodes the channel header and chaincode invocation spec of a proposal or transaction
+func decodeInvocation(signed, channelHeaderBytes, input []byte) ([]byte, string, *OfflineContent, error) {
+	channelHeader := &common.ChannelHeader{}
+	if err := proto.Unmarshal(channelHeaderBytes, channelHeader); err != nil {
+		return nil, "", nil, err
+	}
+	invocation := &peer.ChaincodeInvocationSpec{}
+	if err := proto.Unmarshal(input, invocation); err != nil {
+		return nil, "", nil, err
+	}
+	args := invocation.GetChaincodeSpec().GetInput().GetArgs()
+	if len(args) == 0 {
+		return nil, "", nil, errors.New("no transaction function")
+	}
+
+	content := &OfflineContent{
+		ChannelID:   channelHeader.GetChannelId(),
+		ChaincodeID: invocation.GetChaincodeSpec().GetChaincodeId().GetName(),
+		Function:    string(args[0]),
+	}
+	for _, arg := range args[1:] {
+		content.Args = append(content.Args, string(arg))
+	}
+	return signed, channelHeader.GetTxId(), content, nil
+}
+
+// PrepareOffline creates an unsigned proposal for a transaction function.
+// The gateway must be connected without client.WithSign; sign the returned digest
+// externally and pass the signature to ContinueOffline.
+func (s *AssetService) PrepareOffline(function string, args ...string) (*OfflineRequest, error) {
+	proposal, err := s.contract.NewProposal(function, client.WithArguments(args...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s proposal: %w", function, err)
+	}
+	bytes, err := proposal.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s proposal: %w", function, err)
+	}
+
+	return &OfflineRequest{
+		Stage:         OfflineProposal,
+		TransactionID: proposal.TransactionID(),
+		Function:      function,
+		Bytes:         bytes,
+		Digest:        proposal.Digest(),
+	}, nil
+}
+
+// ContinueOffline attaches signature to request and runs the request's stage:
+// a proposal is endorsed, a transaction is submitted, and a commit waits for its status.
+// For the first two stages it returns the next request to sign; for the commit stage it
+// returns the commit status, together with a *CommitFailedError if the transaction is invalid.
+// Each stage is retried and bounded by timeouts like the online methods.
+func (s *AssetService) ContinueOffline(ctx context.Context, request *OfflineRequest, signature []byte) (*OfflineRequest, *client.Status, error) {
+	if len(signature) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty signature", ErrInvalidOfflineRequest)
+	}
+
+	switch request.Stage {
+	case OfflineProposal:
+		next, err := s.endorseOffline(ctx, request, signature)
+		return next, nil, err
+	case OfflineTransaction:
+		next, err := s.submitOffline(ctx, request, signature)
+		return next, nil, err
+	case OfflineCommit:
+		status, err := s.commitStatusOffline(ctx, request, signature)
+		return nil, status, err
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown stage %q", ErrInvalidOfflineRequest, request.Stage)
+	}
+}
+
+func (s *AssetService) endorseOffline(ctx context.Context, request *OfflineRequest, signature []byte) (*OfflineRequest, error) {
+	proposal, err := s.gateway.NewSignedProposal(request.Bytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOfflineRequest, err)
+	}
+
+	var transaction *client.Transaction
+	err = s.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, s.timeouts.Endorse)
+		defer cancel()
+		transaction, err = proposal.EndorseWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to endorse transaction %s: %w", request.TransactionID, err)
+	}
+
+	bytes, err := transaction.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction %s: %w", request.TransactionID, err)
+	}
+	return &OfflineRequest{
+		Stage:         OfflineTransaction,
+		TransactionID: transaction.TransactionID(),
+		Function:      request.Function,
+		Result:        transaction.Result(),
+		Bytes:         bytes,
+		Digest:        transaction.Digest(),
+	}, nil
+}
+
+func (s *AssetService) submitOffline(ctx context.Context, request *OfflineRequest, signature []byte) (*OfflineRequest, error) {
+	transaction, err := s.gateway.NewSignedTransaction(request.Bytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOfflineRequest, err)
+	}
+
+	var commit *client.Commit
+	err = s.retry.do(ctx, func() (err error) {
+		ctx, cancel := withTimeout(ctx, s.timeouts.Submit)
+		defer cancel()
+		commit, err = transaction.SubmitWithContext(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit transaction %s: %w", request.TransactionID, err)
+	}
+
+	bytes, err := commit.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize commit status request for %s: %w", request.TransactionID, err)
+	}
+	return &OfflineRequest{
+		Stage:         OfflineCommit,
+		TransactionID: commit.TransactionID(),
+		Function:      request.Function,
+		Result:        request.Result,
+		Bytes:         bytes,
+		Digest:        commit.Digest(),
+	}, nil
+}
+
+func (s *AssetService) commitStatusOffline(ctx context.Context, request *OfflineRequest, signature []byte) (*client.Status, error) {
+	commit, err := s.gateway.NewSignedCommit(request.Bytes, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOfflineRequest, err)
+	}
+	return (&Commit{commit: commit, retry: s.retry, timeout: s.timeouts.CommitStatus}).Status(ctx)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/hash"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/gateway"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// newOfflineService returns a service whose gateway has an identity but no signer.
+// The gRPC connection is never dialled by these tests.
+func newOfflineService(t *testing.T) (*AssetService, identity.Sign) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Treasury"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := identity.NewX509Identity("Org1MSP", certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign, err := identity.NewPrivateKeySign(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connection, err := grpc.NewClient("passthrough:///unused", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { connection.Close() })
+	gateway, err := client.Connect(id, client.WithClientConnection(connection))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { gateway.Close() })
+
+	return NewAssetService(gateway, WithRetryPolicy(NoRetry())), sign
+}
+
+func Test_PrepareOffline(t *testing.T) {
+	service, sign := newOfflineService(t)
+
+	request, err := service.PrepareOffline("TransferAsset", "asset1", "Treasury")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if request.Stage != OfflineProposal || request.Function != "TransferAsset" || request.TransactionID == "" {
+		t.Errorf("unexpected request: %+v", request)
+	}
+	if len(request.Digest) != sha256.Size {
+		t.Errorf("expected a SHA-256 digest, got %d bytes", len(request.Digest))
+	}
+
+	var serialized bytes.Buffer
+	if err := request.Write(&serialized); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadOfflineRequest(&serialized)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !bytes.Equal(decoded.Bytes, request.Bytes) || decoded.TransactionID != request.TransactionID {
+		t.Error("expected request to survive a JSON round trip")
+	}
+
+	signature, err := sign(decoded.Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal, err := service.gateway.NewSignedProposal(decoded.Bytes, signature)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if proposal.TransactionID() != request.TransactionID {
+		t.Errorf("expected transaction %s, got %s", request.TransactionID, proposal.TransactionID())
+	}
+}
+
+func Test_ContinueOffline_InvalidRequest(t *testing.T) {
+	service, _ := newOfflineService(t)
+	ctx := context.Background()
+
+	if _, _, err := service.ContinueOffline(ctx, &OfflineRequest{Stage: "approve", Bytes: []byte{1}}, []byte{1}); !errors.Is(err, ErrInvalidOfflineRequest) {
+		t.Errorf("expected %v for unknown stage, got %v", ErrInvalidOfflineRequest, err)
+	}
+	if _, _, err := service.ContinueOffline(ctx, &OfflineRequest{Stage: OfflineProposal, Bytes: []byte{1}}, nil); !errors.Is(err, ErrInvalidOfflineRequest) {
+		t.Errorf("expected %v for missing signature, got %v", ErrInvalidOfflineRequest, err)
+	}
+	if _, _, err := service.ContinueOffline(ctx, &OfflineRequest{Stage: OfflineTransaction, Bytes: []byte("garbage")}, []byte{1}); !errors.Is(err, ErrInvalidOfflineRequest) {
+		t.Errorf("expected %v for malformed transaction, got %v", ErrInvalidOfflineRequest, err)
+	}
+	if _, err := ReadOfflineRequest(bytes.NewReader([]byte(`{"stage":"proposal"}`))); !errors.Is(err, ErrInvalidOfflineRequest) {
+		t.Errorf("expected %v for request without bytes, got %v", ErrInvalidOfflineRequest, err)
+	}
+}
+
+func Test_OfflineRequest_Verify(t *testing.T) {
+	service, _ := newOfflineService(t)
+
+	request, err := service.PrepareOffline("TransferAsset", "asset1", "Treasury")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	content, err := request.Verify()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	expected := OfflineContent{ChannelID: "mychannel", ChaincodeID: "basic", Function: "TransferAsset", Args: []string{"asset1", "Treasury"}}
+	if !reflect.DeepEqual(*content, expected) {
+		t.Errorf("expected %+v, got %+v", expected, *content)
+	}
+
+	transaction := preparedTransaction(t, request)
+	if content, err := transaction.Verify(); err != nil || !reflect.DeepEqual(*content, expected) {
+		t.Errorf("expected transaction %+v, got %+v (%v)", expected, content, err)
+	}
+
+	commitRequest, err := proto.Marshal(&gateway.CommitStatusRequest{ChannelId: "mychannel", TransactionId: "tx1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitBytes, err := proto.Marshal(&gateway.SignedCommitStatusRequest{Request: commitRequest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := &OfflineRequest{Stage: OfflineCommit, TransactionID: "tx1", Bytes: commitBytes, Digest: hash.SHA256(commitRequest)}
+	if content, err := commit.Verify(); err != nil || content.ChannelID != "mychannel" {
+		t.Errorf("expected commit status request on mychannel, got %+v (%v)", content, err)
+	}
+
+	for name, tamper := range map[string]func(r *OfflineRequest){
+		"digest":         func(r *OfflineRequest) { r.Digest = hash.SHA256([]byte("other")) },
+		"transaction ID": func(r *OfflineRequest) { r.TransactionID = "other" },
+		"function":       func(r *OfflineRequest) { r.Function = "DeleteAsset" },
+		"stage":          func(r *OfflineRequest) { r.Stage = OfflineTransaction },
+		"bytes":          func(r *OfflineRequest) { r.Bytes = []byte("garbage") },
+	} {
+		tampered := *request
+		tamper(&tampered)
+		if _, err := tampered.Verify(); !errors.Is(err, ErrInvalidOfflineRequest) {
+			t.Errorf("expected %v for tampered %s, got %v", ErrInvalidOfflineRequest, name, err)
+		}
+	}
+}
+
+// preparedTransaction returns a transaction stage request for the proposal of a proposal stage request,
+// as if it had been endorsed
+func preparedTransaction(t *testing.T, request *OfflineRequest) *OfflineRequest {
+	t.Helper()
+	proposedTransaction := &gateway.ProposedTransaction{}
+	if err := proto.Unmarshal(request.Bytes, proposedTransaction); err != nil {
+		t.Fatal(err)
+	}
+	proposal := &peer.Proposal{}
+	if err := proto.Unmarshal(proposedTransaction.GetProposal().GetProposalBytes(), proposal); err != nil {
+		t.Fatal(err)
+	}
+	header := &common.Header{}
+	if err := proto.Unmarshal(proposal.GetHeader(), header); err != nil {
+		t.Fatal(err)
+	}
+
+	marshal := func(message proto.Message) []byte {
+		data, err := proto.Marshal(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	actionPayload := marshal(&peer.ChaincodeActionPayload{ChaincodeProposalPayload: proposal.GetPayload()})
+	payload := marshal(&common.Payload{
+		Header: header,
+		Data:   marshal(&peer.Transaction{Actions: []*peer.TransactionAction{{Payload: actionPayload}}}),
+	})
+	return &OfflineRequest{
+		Stage:         OfflineTransaction,
+		TransactionID: request.TransactionID,
+		Function:      request.Function,
+		Bytes:         marshal(&gateway.PreparedTransaction{TransactionId: request.TransactionID, Envelope: &common.Envelope{Payload: payload}}),
+		Digest:        hash.SHA256(payload),
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy controls how AssetService retries transient gateway failures.
+// Each stage of a transaction (evaluate, endorse, submit, commit status) is retried
+// on its own, so a retried submit or commit status call reuses the same transaction ID.
+// A transaction that fails validation with a retryable code is endorsed again from scratch.
+// The stages and resubmissions of a transaction share one budget of MaxAttempts-1 retries,
+// so a transaction is endorsed at most MaxAttempts times.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts per stage, including the first. Values below 2 disable retries.
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts
+	Multiplier     float64       // Factor applied to the delay after each attempt
+	Jitter         float64       // Fraction of each delay that is randomized, between 0 and 1
+
+	// Retryable overrides the default classification of errors when set.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewAssetService
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// NoRetry returns a policy that makes a single attempt
+func NoRetry() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 1}
+}
+
+// retryableCodes are gRPC status codes that indicate a transient gateway or peer failure
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.DeadlineExceeded:  true,
+	codes.ResourceExhausted: true,
+}
+
+// retryableValidationCodes are transaction validation codes that a fresh endorsement can resolve
+var retryableValidationCodes = map[peer.TxValidationCode]bool{
+	peer.TxValidationCode_MVCC_READ_CONFLICT:    true,
+	peer.TxValidationCode_PHANTOM_READ_CONFLICT: true,
+}
+
+// IsRetryable reports whether err is a transient failure worth retrying:
+// an unavailable or overloaded peer, a gateway timeout, a read conflict at validation,
+// or an interrupted event stream.
+// Chaincode errors such as ErrAssetNotFound are fatal.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, errEventStreamClosed) {
+		return true
+	}
+
+	var commitErr *CommitFailedError
+	if errors.As(err, &commitErr) {
+		return retryableValidationCodes[commitErr.Status.Code]
+	}
+
+	var assetErr *AssetError
+	if errors.As(err, &assetErr) && assetErr.Kind != nil {
+		return false
+	}
+
+	if statusErr, ok := status.FromError(err); ok {
+		return retryableCodes[statusErr.Code()]
+	}
+	return false
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	var stop noRetry
+	if errors.As(err, &stop) {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// retryBudgetKey is the context key of the retryBudget shared by the retry loops of one transaction
+type retryBudgetKey struct{}
+
+// retryBudget counts the retries left to the retry loops that share it
+type retryBudget struct {
+	remaining atomic.Int64
+}
+
+// withBudget returns a context whose retry loops share one budget of MaxAttempts-1 retries,
+// unless ctx already carries a budget.
+func (p RetryPolicy) withBudget(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(retryBudgetKey{}).(*retryBudget); ok {
+		return ctx
+	}
+	budget := &retryBudget{}
+	budget.remaining.Store(int64(max(p.MaxAttempts-1, 0)))
+	return context.WithValue(ctx, retryBudgetKey{}, budget)
+}
+
+// takeRetry reports whether the budget of ctx, if any, allows another retry, and uses it up.
+func takeRetry(ctx context.Context) bool {
+	budget, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	return !ok || budget.remaining.Add(-1) >= 0
+}
+
+// do calls op until it succeeds, fails with a fatal error, or the attempts are used up.
+// Retries also draw on the retry budget of ctx, if any.
+// It never sleeps past the context deadline; the last error is returned instead.
+func (p RetryPolicy) do(ctx context.Context, op func() error) error {
+	backoff := p.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(err) || !takeRetry(ctx) {
+			return err
+		}
+
+		delay := p.jittered(backoff)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff = p.next(backoff)
+	}
+}
+
+func (p RetryPolicy) next(backoff time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		backoff = time.Duration(float64(backoff) * p.Multiplier)
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+func (p RetryPolicy) jittered(backoff time.Duration) time.Duration {
+	if p.Jitter <= 0 || backoff <= 0 {
+		return backoff
+	}
+	jitter := min(p.Jitter, 1)
+	return time.Duration(float64(backoff) * (1 - jitter*rand.Float64()))
+}
+
+// noRetry marks an error that an enclosing retry loop must return as is,
+// because the stage that produced it has already applied the retry policy.
+type noRetry struct {
+	error
+}
+
+func (e noRetry) Unwrap() error {
+	return e.error
+}
+
+func unwrapNoRetry(err error) error {
+	var stop noRetry
+	if errors.As(err, &stop) {
+		return stop.error
+	}
+	return err
+}
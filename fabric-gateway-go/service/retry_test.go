@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_IsRetryable(t *testing.T) {
+	commitFailed := func(code peer.TxValidationCode) error {
+		return &CommitFailedError{Status: &client.Status{TransactionID: "tx1", Code: code}}
+	}
+
+	for name, testCase := range map[string]struct {
+		err      error
+		expected bool
+	}{
+		"unavailable":        {status.Error(codes.Unavailable, "peer down"), true},
+		"deadline exceeded":  {status.Error(codes.DeadlineExceeded, "endorse timeout"), true},
+		"wrapped":            {fmt.Errorf("failed: %w", status.Error(codes.ResourceExhausted, "busy")), true},
+		"MVCC conflict":      {commitFailed(peer.TxValidationCode_MVCC_READ_CONFLICT), true},
+		"phantom read":       {commitFailed(peer.TxValidationCode_PHANTOM_READ_CONFLICT), true},
+		"endorsement policy": {commitFailed(peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE), false},
+		"chaincode error":    {newAssetError("asset1", gatewayError(t, "the asset asset1 does not exist")), false},
+		"aborted":            {status.Error(codes.Aborted, "failed to endorse"), false},
+		"canceled":           {context.Canceled, false},
+		"plain":              {errors.New("boom"), false},
+		"stop":               {noRetry{status.Error(codes.Unavailable, "peer down")}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			policy := DefaultRetryPolicy()
+			if actual := policy.retryable(testCase.err); actual != testCase.expected {
+				t.Errorf("expected %v, got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func Test_RetryPolicyDo(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "peer down")
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		attempts := 0
+		err := policy.do(context.Background(), func() error {
+			attempts++
+			if attempts < 3 {
+				return unavailable
+			}
+			return nil
+		})
+		if err != nil || attempts != 3 {
+			t.Errorf("expected success after 3 attempts, got %v after %d", err, attempts)
+		}
+	})
+
+	t.Run("stops at max attempts", func(t *testing.T) {
+		attempts := 0
+		err := policy.do(context.Background(), func() error {
+			attempts++
+			return unavailable
+		})
+		if !errors.Is(err, unavailable) || attempts != 4 {
+			t.Errorf("expected %v after 4 attempts, got %v after %d", unavailable, err, attempts)
+		}
+	})
+
+	t.Run("does not retry fatal errors", func(t *testing.T) {
+		attempts := 0
+		fatal := errors.New("fatal")
+		err := policy.do(context.Background(), func() error {
+			attempts++
+			return fatal
+		})
+		if !errors.Is(err, fatal) || attempts != 1 {
+			t.Errorf("expected %v after 1 attempt, got %v after %d", fatal, err, attempts)
+		}
+	})
+
+	t.Run("respects context deadline", func(t *testing.T) {
+		slow := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		attempts := 0
+		start := time.Now()
+		err := slow.do(ctx, func() error {
+			attempts++
+			return unavailable
+		})
+		if !errors.Is(err, unavailable) || attempts != 1 || time.Since(start) > 500*time.Millisecond {
+			t.Errorf("expected immediate %v, got %v after %d attempts", unavailable, err, attempts)
+		}
+	})
+}
+
+// fakeCommit returns a fixed status for a transaction, standing in for the gateway
+type fakeCommit struct {
+	status *client.Status
+}
+
+func (c *fakeCommit) TransactionID() string {
+	return c.status.TransactionID
+}
+
+func (c *fakeCommit) StatusWithContext(context.Context, ...grpc.CallOption) (*client.Status, error) {
+	return c.status, nil
+}
+
+func Test_Resubmit(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	assets := &AssetService{retry: policy, out: io.Discard}
+	commitWith := func(txID string, code peer.TxValidationCode) *Commit {
+		status := &client.Status{TransactionID: txID, Code: code, Successful: code == peer.TxValidationCode_VALID}
+		return &Commit{commit: &fakeCommit{status: status}, retry: policy}
+	}
+
+	t.Run("submits a new transaction after a read conflict", func(t *testing.T) {
+		validationCodes := []peer.TxValidationCode{peer.TxValidationCode_MVCC_READ_CONFLICT, peer.TxValidationCode_VALID}
+		sent := 0
+		status, err := assets.Resubmit(context.Background(), func(context.Context) (*Commit, error) {
+			sent++
+			return commitWith(fmt.Sprintf("tx%d", sent), validationCodes[sent-1]), nil
+		})
+		if err != nil || sent != 2 || status.TransactionID != "tx2" {
+			t.Errorf("expected tx2 to commit after 2 submits, got %v, %v after %d", status, err, sent)
+		}
+	})
+
+	t.Run("does not resubmit other validation failures", func(t *testing.T) {
+		sent := 0
+		status, err := assets.Resubmit(context.Background(), func(context.Context) (*Commit, error) {
+			sent++
+			return commitWith("tx1", peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE), nil
+		})
+		if !errors.Is(err, ErrCommitFailed) || sent != 1 || status.Code != peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE {
+			t.Errorf("expected %v after 1 submit, got %v, %v after %d", ErrCommitFailed, status, err, sent)
+		}
+	})
+
+	t.Run("shares one retry budget with send", func(t *testing.T) {
+		unavailable := status.Error(codes.Unavailable, "peer down")
+		sent, attempts := 0, 0
+		_, err := assets.Resubmit(context.Background(), func(ctx context.Context) (*Commit, error) {
+			sent++
+			// Endorsement fails on every other attempt, and every transaction conflicts
+			err := policy.do(ctx, func() error {
+				attempts++
+				if attempts%2 == 1 {
+					return unavailable
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			return commitWith(fmt.Sprintf("tx%d", sent), peer.TxValidationCode_MVCC_READ_CONFLICT), nil
+		})
+		// A retried endorsement and a resubmission use up both retries of the policy
+		if !errors.Is(err, unavailable) || sent != 2 || attempts != 3 {
+			t.Errorf("expected %v after 2 submits and 3 attempts, got %v after %d and %d", unavailable, err, sent, attempts)
+		}
+	})
+
+	t.Run("does not call send again after it fails", func(t *testing.T) {
+		unavailable := status.Error(codes.Unavailable, "peer down")
+		sent := 0
+		_, err := assets.Resubmit(context.Background(), func(context.Context) (*Commit, error) {
+			sent++
+			return nil, unavailable
+		})
+		if !errors.Is(err, unavailable) || sent != 1 {
+			t.Errorf("expected %v after 1 submit, got %v after %d", unavailable, err, sent)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts are the per-attempt timeouts for each stage of a transaction.
+// A zero value leaves the stage bounded only by the caller's context and the
+// gateway-level timeouts set with client.WithEvaluateTimeout and friends.
+type Timeouts struct {
+	Evaluate     time.Duration
+	Endorse      time.Duration
+	Submit       time.Duration
+	CommitStatus time.Duration
+}
+
+// DefaultTimeouts returns the timeouts used by NewAssetService.
+// They match the gateway options used by the rest-api-go sample.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Evaluate:     5 * time.Second,
+		Endorse:      15 * time.Second,
+		Submit:       5 * time.Second,
+		CommitStatus: 1 * time.Minute,
+	}
+}
+
+// withTimeout derives a context bounded by timeout, or returns ctx unchanged when timeout is zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
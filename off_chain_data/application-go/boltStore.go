@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Names of the buckets that hold the listener's state, which cannot clash with channel names since those start with
+// a letter.
+var (
+	// Maps the ID of each transaction whose writes have been applied to its block number.
+	appliedTransactionsBucket = []byte("_applied_transactions")
+	// Holds the position of the listener under checkpointKey, in the form of client.FileCheckpointer.
+	checkpointBucket = []byte("_checkpoint")
+	checkpointKey    = []byte("checkpoint")
+)
+
+var _ checkpointStore = (*boltStore)(nil)
+
+type boltCheckpoint struct {
+	BlockNumber   uint64 `json:"blockNumber"`
+	TransactionID string `json:"transactionId"`
+}
+
+// Store that materializes the world state in a bbolt database file. Each channel is a bucket holding a bucket per
+// namespace, which maps each key to its current value. Deleted keys are removed.
+// It is also the checkpointer of the listener, and records the checkpoint with the writes of each transaction.
+type boltStore struct {
+	db         *bolt.DB
+	checkpoint boltCheckpoint
+}
+
+func newBoltStore(path string) (*boltStore, error) {
+	// Fail rather than wait forever if another process has the database open
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	store := &boltStore{db: db}
+	err = db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(checkpointBucket)
+		if bucket == nil {
+			return nil
+		}
+		return json.Unmarshal(bucket.Get(checkpointKey), &store.checkpoint)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	return store, nil
+}
+
+// Apply writes for a given transaction to the namespace buckets, unless the transaction was applied before, and
+// checkpoint the transaction in a single database transaction.
+func (s *boltStore) write(data ledgerUpdate) error {
+	checkpoint := boltCheckpoint{data.BlockNumber, data.TransactionID}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := s.apply(tx, data); err != nil {
+			return err
+		}
+		return putCheckpoint(tx, checkpoint)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.checkpoint = checkpoint
+	return nil
+}
+
+func (s *boltStore) apply(tx *bolt.Tx, data ledgerUpdate) error {
+	appliedTransactions, err := tx.CreateBucketIfNotExists(appliedTransactionsBucket)
+	if err != nil {
+		return err
+	}
+	if appliedTransactions.Get([]byte(data.TransactionID)) != nil {
+		fmt.Println("Skipping transaction already in store", data.TransactionID)
+		return nil
+	}
+	if err := appliedTransactions.Put([]byte(data.TransactionID), []byte(strconv.FormatUint(data.BlockNumber, 10))); err != nil {
+		return err
+	}
+
+	for _, write := range data.Writes {
+		channel, err := tx.CreateBucketIfNotExists([]byte(write.ChannelName))
+		if err != nil {
+			return err
+		}
+		namespace, err := channel.CreateBucketIfNotExists([]byte(write.Namespace))
+		if err != nil {
+			return err
+		}
+
+		if write.IsDelete {
+			err = namespace.Delete([]byte(write.Key))
+		} else {
+			err = namespace.Put([]byte(write.Key), write.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func putCheckpoint(tx *bolt.Tx, checkpoint boltCheckpoint) error {
+	bucket, err := tx.CreateBucketIfNotExists(checkpointBucket)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(checkpointKey, value)
+}
+
+func (s *boltStore) CheckpointTransaction(blockNumber uint64, transactionID string) error {
+	checkpoint := boltCheckpoint{blockNumber, transactionID}
+	if checkpoint == s.checkpoint {
+		// Already checkpointed by write
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return putCheckpoint(tx, checkpoint)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.checkpoint = checkpoint
+	return nil
+}
+
+func (s *boltStore) CheckpointBlock(blockNumber uint64) error {
+	return s.CheckpointTransaction(blockNumber+1, "")
+}
+
+func (s *boltStore) BlockNumber() uint64 {
+	return s.checkpoint.BlockNumber
+}
+
+func (s *boltStore) TransactionID() string {
+	return s.checkpoint.TransactionID
+}
+
+func (s *boltStore) close() error {
+	return s.db.Close()
+}
@@ -27,15 +27,36 @@ func listen(clientConnection grpc.ClientConnInterface) error {
 		fmt.Println("Gateway closed.")
 	}()
 
-	checkpointFile := envOrDefault("CHECKPOINT_FILE", "checkpoint.json")
-	checkpointer, err := client.NewFileCheckpointer(checkpointFile)
+	storeType := envOrDefault("STORE_TYPE", fileStoreType)
+	baseStore, err := newStore(storeType, os.Getenv("STORE_URL"))
 	if err != nil {
 		return err
 	}
 	defer func() {
-		checkpointer.Close()
-		fmt.Println("Checkpointer closed.")
+		if err := baseStore.close(); err != nil {
+			fmt.Println("Failed to close store:", err)
+		}
+		fmt.Println("Store closed.")
 	}()
+	fmt.Println("Writing to", storeType, "store")
+
+	var checkpointer transactionCheckpointer
+	if storeCheckpointer, ok := baseStore.(checkpointStore); ok {
+		// The store records the checkpoint with the writes of each transaction
+		checkpointer = storeCheckpointer
+		fmt.Println("Checkpointing in", storeType, "store")
+	} else {
+		checkpointFile := envOrDefault("CHECKPOINT_FILE", "checkpoint.json")
+		fileCheckpointer, err := client.NewFileCheckpointer(checkpointFile)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			fileCheckpointer.Close()
+			fmt.Println("Checkpointer closed.")
+		}()
+		checkpointer = fileCheckpointer
+	}
 	fmt.Println("Start event listening from block", checkpointer.BlockNumber())
 	fmt.Println("Last processed transaction ID within block:", checkpointer.TransactionID())
 
@@ -43,8 +64,7 @@ func listen(clientConnection grpc.ClientConnInterface) error {
 	if simulatedFailureCount > 0 {
 		fmt.Printf("Simulating a write failure every %d transactions\n", simulatedFailureCount)
 	}
-	storeFile := envOrDefault("STORE_FILE", "store.log")
-	offChainStore := newOffChainStore(storeFile, simulatedFailureCount)
+	offChainStore := newSimulatedFailureStore(baseStore, simulatedFailureCount)
 
 	ctx, close := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer func() {
@@ -92,15 +112,32 @@ func initSimulatedFailureCount() uint {
 }
 
 // Apply writes for a given transaction to off-chain data store, ideally in a single operation for fault tolerance.
+// Writing a transaction that the store already holds has no effect, so that replayed transactions are harmless.
 type store interface {
 	write(ledgerUpdate) error
+	close() error
+}
+
+// Persists the position of the listener, so that listening resumes after the last processed transaction.
+// Implemented by client.FileCheckpointer.
+type transactionCheckpointer interface {
+	client.Checkpoint
+	CheckpointTransaction(blockNumber uint64, transactionID string) error
+	CheckpointBlock(blockNumber uint64) error
+}
+
+// Store that checkpoints each transaction it writes in the same atomic operation as the writes, so that a failure
+// cannot leave the store and the checkpoint out of step.
+type checkpointStore interface {
+	store
+	transactionCheckpointer
 }
 
 // Ledger update made by a specific transaction.
 type ledgerUpdate struct {
-	BlockNumber   uint64
-	TransactionID string
-	Writes        []write
+	BlockNumber   uint64  `json:"blockNumber"`
+	TransactionID string  `json:"transactionId"`
+	Writes        []write `json:"writes"`
 }
 
 // Description of a ledger Write that can be applied to an off-chain data store.
@@ -113,13 +150,15 @@ type write struct {
 	Key string `json:"key"`
 	// Whether the key and associated value are being deleted.
 	IsDelete bool `json:"isDelete"`
-	// If `isDelete` is false, the Value written to the key; otherwise ignored.
-	Value string `json:"value"`
+	// If `isDelete` is false, the Value written to the key; otherwise ignored. It holds the bytes written by the
+	// chaincode, which need not be valid UTF-8, so stores that require text must store it as binary data. JSON
+	// encodes it as base64.
+	Value []byte `json:"value"`
 }
 
 type blockProcessor struct {
 	parsedBlock  *parser.Block
-	checkpointer *client.FileCheckpointer
+	checkpointer transactionCheckpointer
 	store        store
 }
 
@@ -265,7 +304,7 @@ func (t *transactionProcessor) writes() ([]write, error) {
 			return nil, err
 		}
 
-		result = t.newWrites(kvReadWriteSet, nsReadWriteSet.Namespace())
+		result = append(result, t.newWrites(kvReadWriteSet, nsReadWriteSet.Namespace())...)
 	}
 
 	return result, nil
@@ -302,7 +341,7 @@ func (t *transactionProcessor) newWrites(kvReadWriteSet *kvrwset.KVRWSet, namesp
 			Namespace:   namespace,
 			Key:         kvWrite.GetKey(),
 			IsDelete:    kvWrite.GetIsDelete(),
-			Value:       string(kvWrite.GetValue()), // Convert bytes to text, purely for readability in output
+			Value:       kvWrite.GetValue(),
 		})
 	}
 
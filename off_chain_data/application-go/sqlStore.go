@@ -0,0 +1,188 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// Statements that create the tables, given the column type of binary data, which is BLOB in SQLite and BYTEA in
+// PostgreSQL.
+func createTables(binaryType string) []string {
+	return []string{
+		// The world state replica holds the current value of each key, with the block and transaction that last wrote
+		// it. Deleted keys are removed. Values are stored as written by the chaincode, which need not be valid text.
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS world_state (
+			channel_name   TEXT   NOT NULL,
+			namespace      TEXT   NOT NULL,
+			key            TEXT   NOT NULL,
+			value          %s     NOT NULL,
+			block_number   BIGINT NOT NULL,
+			transaction_id TEXT   NOT NULL,
+			PRIMARY KEY (channel_name, namespace, key)
+		)`, binaryType),
+		// Transactions whose writes have been applied, so that a replayed transaction is not applied again.
+		`CREATE TABLE IF NOT EXISTS applied_transactions (
+			transaction_id TEXT   NOT NULL PRIMARY KEY,
+			block_number   BIGINT NOT NULL
+		)`,
+		// A single row holding the position of the listener, in the form of client.FileCheckpointer.
+		`CREATE TABLE IF NOT EXISTS checkpoint (
+			id             INTEGER NOT NULL PRIMARY KEY CHECK (id = 1),
+			block_number   BIGINT  NOT NULL,
+			transaction_id TEXT    NOT NULL
+		)`,
+	}
+}
+
+const upsertWorldState = `INSERT INTO world_state (channel_name, namespace, key, value, block_number, transaction_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (channel_name, namespace, key) DO UPDATE
+SET value = excluded.value, block_number = excluded.block_number, transaction_id = excluded.transaction_id`
+
+const deleteWorldState = `DELETE FROM world_state WHERE channel_name = $1 AND namespace = $2 AND key = $3`
+
+const insertAppliedTransaction = `INSERT INTO applied_transactions (transaction_id, block_number) VALUES ($1, $2)
+ON CONFLICT (transaction_id) DO NOTHING`
+
+const selectCheckpoint = `SELECT block_number, transaction_id FROM checkpoint WHERE id = 1`
+
+const upsertCheckpoint = `INSERT INTO checkpoint (id, block_number, transaction_id) VALUES (1, $1, $2)
+ON CONFLICT (id) DO UPDATE SET block_number = excluded.block_number, transaction_id = excluded.transaction_id`
+
+var _ checkpointStore = (*sqlStore)(nil)
+
+// Store that materializes the world state in a world_state table of a SQL database, such as SQLite or PostgreSQL.
+// It is also the checkpointer of the listener, and records the checkpoint with the writes of each transaction.
+type sqlStore struct {
+	db            *sql.DB
+	blockNumber   uint64
+	transactionID string
+}
+
+func newSQLStore(driverName, dataSourceName, binaryType string) (*sqlStore, error) {
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, createTable := range createTables(binaryType) {
+		if _, err := db.Exec(createTable); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to create tables: %w", err), db.Close())
+		}
+	}
+
+	store := &sqlStore{db: db}
+	err = db.QueryRow(selectCheckpoint).Scan(&store.blockNumber, &store.transactionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Join(fmt.Errorf("failed to read checkpoint: %w", err), db.Close())
+	}
+
+	return store, nil
+}
+
+// Create a store in the PostgreSQL database at the given connection URL.
+func newPostgresStore(url string) (*sqlStore, error) {
+	return newSQLStore("pgx", url, "BYTEA")
+}
+
+// Create a store in the SQLite database file at path. The database is in WAL mode, so that reporting queries can
+// read it while the listener writes.
+func newSQLiteStore(path string) (*sqlStore, error) {
+	store, err := newSQLStore("sqlite", path, "BLOB")
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows a single writer
+	store.db.SetMaxOpenConns(1)
+	if _, err := store.db.Exec("PRAGMA journal_mode = WAL"); err != nil {
+		return nil, errors.Join(err, store.close())
+	}
+
+	return store, nil
+}
+
+// Apply writes for a given transaction to the world_state table, unless the transaction was applied before, and
+// checkpoint the transaction in a single database transaction.
+func (s *sqlStore) write(data ledgerUpdate) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := s.apply(tx, data); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if _, err := tx.Exec(upsertCheckpoint, data.BlockNumber, data.TransactionID); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.blockNumber, s.transactionID = data.BlockNumber, data.TransactionID
+	return nil
+}
+
+func (s *sqlStore) apply(tx *sql.Tx, data ledgerUpdate) error {
+	result, err := tx.Exec(insertAppliedTransaction, data.TransactionID, data.BlockNumber)
+	if err != nil {
+		return err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if inserted == 0 {
+		fmt.Println("Skipping transaction already in store", data.TransactionID)
+		return nil
+	}
+
+	for _, write := range data.Writes {
+		if write.IsDelete {
+			_, err = tx.Exec(deleteWorldState, write.ChannelName, write.Namespace, write.Key)
+		} else {
+			// Bind the value as bytes, since PostgreSQL rejects text that is not valid UTF-8 or contains NUL characters
+			_, err = tx.Exec(upsertWorldState, write.ChannelName, write.Namespace, write.Key, write.Value, data.BlockNumber, data.TransactionID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *sqlStore) CheckpointTransaction(blockNumber uint64, transactionID string) error {
+	if blockNumber == s.blockNumber && transactionID == s.transactionID {
+		// Already checkpointed by write
+		return nil
+	}
+
+	if _, err := s.db.Exec(upsertCheckpoint, blockNumber, transactionID); err != nil {
+		return err
+	}
+
+	s.blockNumber, s.transactionID = blockNumber, transactionID
+	return nil
+}
+
+func (s *sqlStore) CheckpointBlock(blockNumber uint64) error {
+	return s.CheckpointTransaction(blockNumber+1, "")
+}
+
+func (s *sqlStore) BlockNumber() uint64 {
+	return s.blockNumber
+}
+
+func (s *sqlStore) TransactionID() string {
+	return s.transactionID
+}
+
+func (s *sqlStore) close() error {
+	return s.db.Close()
+}
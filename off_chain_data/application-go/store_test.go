@@ -0,0 +1,288 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Key of a world state entry.
+type stateKey struct {
+	namespace, key string
+}
+
+var worldStateUpdates = []ledgerUpdate{
+	{
+		BlockNumber:   1,
+		TransactionID: "tx1",
+		Writes: []write{
+			{ChannelName: "mychannel", Namespace: "basic", Key: "asset1", Value: []byte(`{"ID":"asset1","Owner":"alice"}`)},
+			{ChannelName: "mychannel", Namespace: "basic", Key: "asset2", Value: []byte(`{"ID":"asset2","Owner":"bob"}`)},
+			{ChannelName: "mychannel", Namespace: "other", Key: "asset1", Value: []byte("other value")},
+			// Chaincode values need not be valid UTF-8
+			{ChannelName: "mychannel", Namespace: "other", Key: "binary", Value: []byte("\x00\xff\xfe binary")},
+		},
+	},
+	{
+		BlockNumber:   2,
+		TransactionID: "tx2",
+		Writes: []write{
+			{ChannelName: "mychannel", Namespace: "basic", Key: "asset1", Value: []byte(`{"ID":"asset1","Owner":"charlie"}`)},
+			{ChannelName: "mychannel", Namespace: "basic", Key: "asset2", IsDelete: true},
+		},
+	},
+}
+
+var expectedWorldState = map[stateKey]string{
+	{"basic", "asset1"}: `{"ID":"asset1","Owner":"charlie"}`,
+	{"other", "asset1"}: "other value",
+	{"other", "binary"}: "\x00\xff\xfe binary",
+}
+
+// Write the updates, then replay the first, which must have no effect.
+func writeWorldStateUpdates(t *testing.T, s store) {
+	t.Helper()
+	for _, update := range append(worldStateUpdates, worldStateUpdates[0]) {
+		if err := s.write(update); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+}
+
+// Check that the store checkpoints each transaction it writes, and keeps the checkpoint when reopened.
+func testStoreCheckpoint(t *testing.T, open func() (store, error)) {
+	t.Helper()
+	s, err := open()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	checkpointer, ok := s.(checkpointStore)
+	if !ok {
+		t.Fatalf("expected %T to checkpoint", s)
+	}
+	if checkpointer.BlockNumber() != 0 || checkpointer.TransactionID() != "" {
+		t.Fatalf("expected empty checkpoint, got block %d, transaction %s", checkpointer.BlockNumber(), checkpointer.TransactionID())
+	}
+	if err := s.write(worldStateUpdates[0]); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if err := s.close(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	s, err = open()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.close()
+	checkpointer = s.(checkpointStore)
+	if checkpointer.BlockNumber() != 1 || checkpointer.TransactionID() != "tx1" {
+		t.Fatalf("expected checkpoint at tx1 in block 1, got block %d, transaction %s", checkpointer.BlockNumber(), checkpointer.TransactionID())
+	}
+	if err := checkpointer.CheckpointBlock(1); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if checkpointer.BlockNumber() != 2 || checkpointer.TransactionID() != "" {
+		t.Fatalf("expected checkpoint at block 2, got block %d, transaction %s", checkpointer.BlockNumber(), checkpointer.TransactionID())
+	}
+}
+
+func sqlWorldState(t *testing.T, s *sqlStore) map[stateKey]string {
+	t.Helper()
+	rows, err := s.db.Query("SELECT namespace, key, value FROM world_state WHERE channel_name = 'mychannel'")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer rows.Close()
+
+	result := map[stateKey]string{}
+	for rows.Next() {
+		var namespace, key, value string
+		if err := rows.Scan(&namespace, &key, &value); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		result[stateKey{namespace, key}] = value
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return result
+}
+
+func Test_SQLiteStoreMaterializesWorldState(t *testing.T) {
+	s, err := newStore(sqliteStoreType, filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.close()
+
+	writeWorldStateUpdates(t, s)
+
+	if actual := sqlWorldState(t, s.(*sqlStore)); !reflect.DeepEqual(actual, expectedWorldState) {
+		t.Fatalf("expected world state %v, got %v", expectedWorldState, actual)
+	}
+	var blockNumber uint64
+	var transactionID string
+	row := s.(*sqlStore).db.QueryRow("SELECT block_number, transaction_id FROM world_state WHERE namespace = 'basic' AND key = 'asset1'")
+	if err := row.Scan(&blockNumber, &transactionID); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if blockNumber != 2 || transactionID != "tx2" {
+		t.Fatalf("expected asset1 last written by tx2 in block 2, got %s in block %d", transactionID, blockNumber)
+	}
+}
+
+// Return the URL of the PostgreSQL database at STORE_TEST_POSTGRES_URL, after dropping the tables of the store, or
+// skip the test if it is not set. The off-chain CI script sets it to a PostgreSQL container.
+func postgresTestURL(t *testing.T) string {
+	t.Helper()
+	url := os.Getenv("STORE_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("STORE_TEST_POSTGRES_URL not set")
+	}
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS world_state, applied_transactions, checkpoint"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	return url
+}
+
+func Test_PostgresStoreMaterializesWorldState(t *testing.T) {
+	s, err := newStore(postgresStoreType, postgresTestURL(t))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.close()
+
+	writeWorldStateUpdates(t, s)
+
+	if actual := sqlWorldState(t, s.(*sqlStore)); !reflect.DeepEqual(actual, expectedWorldState) {
+		t.Fatalf("expected world state %v, got %v", expectedWorldState, actual)
+	}
+}
+
+func Test_BoltStoreMaterializesWorldState(t *testing.T) {
+	s, err := newStore(bboltStoreType, filepath.Join(t.TempDir(), "store.bolt"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.close()
+
+	writeWorldStateUpdates(t, s)
+
+	actual := map[stateKey]string{}
+	err = s.(*boltStore).db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte("mychannel")).ForEachBucket(func(namespace []byte) error {
+			return tx.Bucket([]byte("mychannel")).Bucket(namespace).ForEach(func(key, value []byte) error {
+				actual[stateKey{string(namespace), string(key)}] = string(value)
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !reflect.DeepEqual(actual, expectedWorldState) {
+		t.Fatalf("expected world state %v, got %v", expectedWorldState, actual)
+	}
+}
+
+func Test_SQLiteStoreCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	testStoreCheckpoint(t, func() (store, error) {
+		return newStore(sqliteStoreType, path)
+	})
+}
+
+func Test_PostgresStoreCheckpoint(t *testing.T) {
+	url := postgresTestURL(t)
+	testStoreCheckpoint(t, func() (store, error) {
+		return newStore(postgresStoreType, url)
+	})
+}
+
+func Test_BoltStoreCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.bolt")
+	testStoreCheckpoint(t, func() (store, error) {
+		return newStore(bboltStoreType, path)
+	})
+}
+
+func Test_FileStoreSkipsStoredTransactions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.log")
+	s, err := newOffChainStore(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	writeWorldStateUpdates(t, s)
+
+	// Reopen after a failed write that left an incomplete line
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := f.WriteString(`{"blockNumber":3,"transactionId":"tx3","wri`); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	f.Close()
+	s, err = newOffChainStore(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	// Only the transactions of the last block are held in memory
+	if expected := map[string]bool{"tx2": true}; s.blockNumber != 2 || !reflect.DeepEqual(s.transactionIDs, expected) {
+		t.Fatalf("expected transactions %v of block 2, got %v of block %d", expected, s.transactionIDs, s.blockNumber)
+	}
+	writeWorldStateUpdates(t, s)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	var actual []ledgerUpdate
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		var update ledgerUpdate
+		if err := json.Unmarshal([]byte(line), &update); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		actual = append(actual, update)
+	}
+	// Values, including those that are not valid UTF-8, read back as written
+	if !reflect.DeepEqual(actual, worldStateUpdates) {
+		t.Fatalf("expected updates %v in store, got %v", worldStateUpdates, actual)
+	}
+}
+
+func Test_NewStoreRejectsUnknownType(t *testing.T) {
+	if _, err := newStore("badger", ""); err == nil {
+		t.Fatal("expected error for unknown store type")
+	}
+	if _, err := newStore(postgresStoreType, ""); err == nil {
+		t.Fatal("expected error for postgres store without URL")
+	}
+}
+
+func Test_SimulatedFailureStore(t *testing.T) {
+	fileStore, err := newOffChainStore(filepath.Join(t.TempDir(), "store.log"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	s := newSimulatedFailureStore(fileStore, 2)
+
+	for i, expected := range []error{nil, nil, errExpected, nil, nil, errExpected} {
+		if err := s.write(worldStateUpdates[0]); !errors.Is(err, expected) {
+			t.Fatalf("write %d: expected %v, got %v", i, expected, err)
+		}
+	}
+}